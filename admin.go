@@ -0,0 +1,248 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxAuditEntries is how many admin actions are kept in memory
+const maxAuditEntries = 500
+
+var errUnauthorized = errors.New("unauthorized")
+
+// AuditEntry is a record of an admin action
+type AuditEntry struct {
+	Date       time.Time `json:"date"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	Target     string    `json:"target,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLog keeps the latest admin actions, oldest first
+type auditLog struct {
+	sync.Mutex
+	entries []AuditEntry
+}
+
+func (a *auditLog) add(e AuditEntry) {
+	fields := log.Fields{
+		"actor":       e.Actor,
+		"action":      e.Action,
+		"target":      e.Target,
+		"detail":      e.Detail,
+		"remote_addr": e.RemoteAddr,
+	}
+	if e.Error != "" {
+		log.WithFields(fields).WithField("error", e.Error).Warn("admin action failed")
+	} else {
+		log.WithFields(fields).Info("admin action")
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	a.entries = append(a.entries, e)
+	if len(a.entries) > maxAuditEntries {
+		a.entries = a.entries[len(a.entries)-maxAuditEntries:]
+	}
+}
+
+func (a *auditLog) list() []AuditEntry {
+	a.Lock()
+	defer a.Unlock()
+	return append([]AuditEntry{}, a.entries...)
+}
+
+// audit records an admin action taken by the request r
+func (h *Handler) audit(r *http.Request, action, target, detail string, err error) {
+	e := AuditEntry{
+		Date:       time.Now().UTC(),
+		Actor:      adminActor(r),
+		Action:     action,
+		Target:     target,
+		Detail:     detail,
+		RemoteAddr: r.RemoteAddr,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	h.auditLog.add(e)
+}
+
+// adminActor is who the admin says they are. The token is shared, so
+// this is only as trustworthy as the people holding it.
+func adminActor(r *http.Request) string {
+	if actor := r.Header.Get("X-Admin-Actor"); actor != "" {
+		return actor
+	}
+	return "admin"
+}
+
+// requireAdmin only calls next for requests with the admin token as a
+// bearer token. Admin routes are disabled when no token is configured.
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.AdminToken == "" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("admin api disabled"))
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.AdminToken)) != 1 {
+			h.audit(r, "unauthorized", r.Method+" "+r.URL.Path, "", errUnauthorized)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(errUnauthorized.Error()))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminStatus is the HTTP status for an error from an admin action
+func adminStatus(err error) int {
+	switch err {
+	case game.ErrPlayerNotFound, errWebhookNotFound, errBotNotFound:
+		return http.StatusNotFound
+	case game.ErrNoGameInProgress:
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// writeAdmin audits the action and writes the result
+func (h *Handler) writeAdmin(w http.ResponseWriter, r *http.Request, action, target, detail string, err error) {
+	h.audit(r, action, target, detail, err)
+	if err != nil {
+		w.WriteHeader(adminStatus(err))
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// AdminKick removes a player, who may subscribe again
+func (h *Handler) AdminKick(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.writeAdmin(w, r, "kick", id, "", h.game.Kick(id))
+}
+
+// AdminBan removes a player and stops them subscribing again
+func (h *Handler) AdminBan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.writeAdmin(w, r, "ban", id, "", h.game.Ban(id))
+}
+
+// AdminBans lists the ids of banned players
+func (h *Handler) AdminBans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.game.Bans())
+}
+
+// AdminUnban lets a banned player subscribe again
+func (h *Handler) AdminUnban(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.writeAdmin(w, r, "unban", id, "", h.game.Unban(id))
+}
+
+// AdminNameRules gets the rules for player names
+func (h *Handler) AdminNameRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.game.NameRules())
+}
+
+// AdminSetNameRules replaces the rules for player names
+func (h *Handler) AdminSetNameRules(w http.ResponseWriter, r *http.Request) {
+	var rules game.NameRules
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	detail, _ := json.Marshal(rules)
+	h.writeAdmin(w, r, "set_name_rules", "", string(detail), h.game.SetNameRules(rules))
+}
+
+// AdminMoveInQueue moves a queued player to a position in the queue,
+// where 0 is next to play
+func (h *Handler) AdminMoveInQueue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Position *int `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+	if req.Position == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("need position"))
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	detail := "position " + strconv.Itoa(*req.Position)
+	h.writeAdmin(w, r, "move_in_queue", id, detail, h.game.MoveInQueue(id, *req.Position))
+}
+
+// AdminForceResult ends the game in progress with the given result
+func (h *Handler) AdminForceResult(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Result game.Status `json:"result"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+	h.writeAdmin(w, r, "force_result", "", string(req.Result), h.game.ForceResult(req.Result))
+}
+
+// AdminPause stops the move timeout
+func (h *Handler) AdminPause(w http.ResponseWriter, r *http.Request) {
+	h.game.PauseTimeout()
+	h.writeAdmin(w, r, "pause_timeout", "", "", nil)
+}
+
+// AdminResume restarts the move timeout
+func (h *Handler) AdminResume(w http.ResponseWriter, r *http.Request) {
+	h.game.ResumeTimeout()
+	h.writeAdmin(w, r, "resume_timeout", "", "", nil)
+}
+
+// AdminBroadcast sends a message to everyone following the game
+func (h *Handler) AdminBroadcast(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+	h.writeAdmin(w, r, "broadcast", "", req.Message, h.game.Broadcast(req.Message))
+}
+
+// AdminAudit lists admin actions, oldest first
+func (h *Handler) AdminAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.auditLog.list())
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+const (
+	// defaultAnalysisIterations are the playouts made when a request
+	// sets no limit
+	defaultAnalysisIterations = 10000
+	// maxAnalysisIterations and maxAnalysisTime bound the search a
+	// request can ask for
+	maxAnalysisIterations = 200000
+	maxAnalysisTime       = 5 * time.Second
+)
+
+// AnalysisRequest asks for a position of any variant to be searched
+type AnalysisRequest struct {
+	game.Position
+	// Iterations and MoveTimeMS limit the search, to at most
+	// maxAnalysisIterations playouts and maxAnalysisTime
+	Iterations int `json:"iterations"`
+	MoveTimeMS int `json:"movetime_ms"`
+}
+
+// Analysis is what the search found out about each move of a position
+type Analysis struct {
+	Variant game.Variant `json:"variant"`
+	Side    string       `json:"side"`
+	// Moves are the empty squares within two squares of a piece, every
+	// square of an empty board, the most tried first
+	Moves []game.MoveStat `json:"moves"`
+}
+
+// position fills in what the request left out: the standard variant, an
+// empty board, and the side whose turn it is by the pieces on the board
+func (req AnalysisRequest) position() game.Position {
+	p := req.Position
+	if p.Variant == (game.Variant{}) {
+		p.Variant = game.StandardVariant
+	}
+	if p.Board == nil {
+		p.Board = game.NewPosition(p.Variant).Board
+	}
+	if p.Side == "" {
+		p.Side = "X"
+		pieces := 0
+		for _, row := range p.Board {
+			for _, piece := range row {
+				pieces += int(piece)
+			}
+		}
+		// X is -1, so there is one more X when it is O's turn
+		if pieces < 0 {
+			p.Side = "O"
+		}
+	}
+	return p
+}
+
+// Analyze searches a position with the MCTS engine and returns the
+// playouts and score of each move. The search stops early when the
+// request is cancelled.
+func (h *Handler) Analyze(w http.ResponseWriter, r *http.Request) {
+	var req AnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	e := game.NewMCTSEngine(time.Now().UnixNano())
+	switch {
+	case req.Iterations < 0 || req.MoveTimeMS < 0:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("iterations and movetime_ms can't be negative"))
+		return
+	case req.Iterations > maxAnalysisIterations:
+		e.Iterations = maxAnalysisIterations
+	case req.Iterations > 0:
+		e.Iterations = req.Iterations
+	case req.MoveTimeMS == 0:
+		e.Iterations = defaultAnalysisIterations
+	}
+	e.MoveTime = maxAnalysisTime
+	if t := time.Duration(req.MoveTimeMS) * time.Millisecond; t > 0 && t < maxAnalysisTime {
+		e.MoveTime = t
+	}
+
+	p := req.position()
+	moves, err := e.AnalyzePosition(r.Context(), p)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Analysis{Variant: p.Variant, Side: p.Side, Moves: moves})
+}
@@ -0,0 +1,8 @@
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI specification of the HTTP API registered by Route
+//
+//go:embed openapi.json
+var OpenAPI []byte
@@ -0,0 +1,1023 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
+// source: tictactoe.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Status int32
+
+const (
+	Status_STATUS_UNSPECIFIED   Status = 0
+	Status_INSUFFICIENT_PLAYERS Status = 1
+	Status_NO_BOARD             Status = 2
+	Status_X_WINS               Status = 3
+	Status_O_WINS               Status = 4
+	Status_CATS                 Status = 5
+	Status_IN_PROGRESS          Status = 6
+)
+
+// Enum value maps for Status.
+var (
+	Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "INSUFFICIENT_PLAYERS",
+		2: "NO_BOARD",
+		3: "X_WINS",
+		4: "O_WINS",
+		5: "CATS",
+		6: "IN_PROGRESS",
+	}
+	Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED":   0,
+		"INSUFFICIENT_PLAYERS": 1,
+		"NO_BOARD":             2,
+		"X_WINS":               3,
+		"O_WINS":               4,
+		"CATS":                 5,
+		"IN_PROGRESS":          6,
+	}
+)
+
+func (x Status) Enum() *Status {
+	p := new(Status)
+	*p = x
+	return p
+}
+
+func (x Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_tictactoe_proto_enumTypes[0].Descriptor()
+}
+
+func (Status) Type() protoreflect.EnumType {
+	return &file_tictactoe_proto_enumTypes[0]
+}
+
+func (x Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Status.Descriptor instead.
+func (Status) EnumDescriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{0}
+}
+
+type Player struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Bot           bool                   `protobuf:"varint,3,opt,name=bot,proto3" json:"bot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Player) Reset() {
+	*x = Player{}
+	mi := &file_tictactoe_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Player) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Player) ProtoMessage() {}
+
+func (x *Player) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Player.ProtoReflect.Descriptor instead.
+func (*Player) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Player) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Player) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *Player) GetBot() bool {
+	if x != nil {
+		return x.Bot
+	}
+	return false
+}
+
+type Move struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId      string                 `protobuf:"bytes,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	XAxis         int32                  `protobuf:"varint,2,opt,name=x_axis,json=xAxis,proto3" json:"x_axis,omitempty"`
+	YAxis         int32                  `protobuf:"varint,3,opt,name=y_axis,json=yAxis,proto3" json:"y_axis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Move) Reset() {
+	*x = Move{}
+	mi := &file_tictactoe_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Move) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Move) ProtoMessage() {}
+
+func (x *Move) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Move.ProtoReflect.Descriptor instead.
+func (*Move) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Move) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *Move) GetXAxis() int32 {
+	if x != nil {
+		return x.XAxis
+	}
+	return 0
+}
+
+func (x *Move) GetYAxis() int32 {
+	if x != nil {
+		return x.YAxis
+	}
+	return 0
+}
+
+type Game struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Board         []int32                `protobuf:"varint,1,rep,packed,name=board,proto3" json:"board,omitempty"`
+	Queue         []*Player              `protobuf:"bytes,2,rep,name=queue,proto3" json:"queue,omitempty"`
+	PlayerX       *Player                `protobuf:"bytes,3,opt,name=player_x,json=playerX,proto3" json:"player_x,omitempty"`
+	PlayerO       *Player                `protobuf:"bytes,4,opt,name=player_o,json=playerO,proto3" json:"player_o,omitempty"`
+	Move          string                 `protobuf:"bytes,5,opt,name=move,proto3" json:"move,omitempty"`
+	Status        Status                 `protobuf:"varint,6,opt,name=status,proto3,enum=tictactoe.Status" json:"status,omitempty"`
+	History       []*Move                `protobuf:"bytes,7,rep,name=history,proto3" json:"history,omitempty"`
+	MoveDeadline  *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=move_deadline,json=moveDeadline,proto3" json:"move_deadline,omitempty"`
+	Paused        bool                   `protobuf:"varint,9,opt,name=paused,proto3" json:"paused,omitempty"`
+	Announcement  string                 `protobuf:"bytes,10,opt,name=announcement,proto3" json:"announcement,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Game) Reset() {
+	*x = Game{}
+	mi := &file_tictactoe_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Game) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Game) ProtoMessage() {}
+
+func (x *Game) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Game.ProtoReflect.Descriptor instead.
+func (*Game) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Game) GetBoard() []int32 {
+	if x != nil {
+		return x.Board
+	}
+	return nil
+}
+
+func (x *Game) GetQueue() []*Player {
+	if x != nil {
+		return x.Queue
+	}
+	return nil
+}
+
+func (x *Game) GetPlayerX() *Player {
+	if x != nil {
+		return x.PlayerX
+	}
+	return nil
+}
+
+func (x *Game) GetPlayerO() *Player {
+	if x != nil {
+		return x.PlayerO
+	}
+	return nil
+}
+
+func (x *Game) GetMove() string {
+	if x != nil {
+		return x.Move
+	}
+	return ""
+}
+
+func (x *Game) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *Game) GetHistory() []*Move {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *Game) GetMoveDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MoveDeadline
+	}
+	return nil
+}
+
+func (x *Game) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Game) GetAnnouncement() string {
+	if x != nil {
+		return x.Announcement
+	}
+	return ""
+}
+
+type GetGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGameRequest) Reset() {
+	*x = GetGameRequest{}
+	mi := &file_tictactoe_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGameRequest) ProtoMessage() {}
+
+func (x *GetGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGameRequest.ProtoReflect.Descriptor instead.
+func (*GetGameRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{3}
+}
+
+type MoveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Move          *Move                  `protobuf:"bytes,1,opt,name=move,proto3" json:"move,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveRequest) Reset() {
+	*x = MoveRequest{}
+	mi := &file_tictactoe_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveRequest) ProtoMessage() {}
+
+func (x *MoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveRequest.ProtoReflect.Descriptor instead.
+func (*MoveRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MoveRequest) GetMove() *Move {
+	if x != nil {
+		return x.Move
+	}
+	return nil
+}
+
+type MoveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveResponse) Reset() {
+	*x = MoveResponse{}
+	mi := &file_tictactoe_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveResponse) ProtoMessage() {}
+
+func (x *MoveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveResponse.ProtoReflect.Descriptor instead.
+func (*MoveResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{5}
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Player        *Player                `protobuf:"bytes,1,opt,name=player,proto3" json:"player,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_tictactoe_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SubscribeRequest) GetPlayer() *Player {
+	if x != nil {
+		return x.Player
+	}
+	return nil
+}
+
+type SubscribeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	mi := &file_tictactoe_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SubscribeResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnsubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeRequest) Reset() {
+	*x = UnsubscribeRequest{}
+	mi := &file_tictactoe_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeRequest) ProtoMessage() {}
+
+func (x *UnsubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UnsubscribeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnsubscribeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeResponse) Reset() {
+	*x = UnsubscribeResponse{}
+	mi := &file_tictactoe_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeResponse) ProtoMessage() {}
+
+func (x *UnsubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{9}
+}
+
+type UpdatePlayerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Player        *Player                `protobuf:"bytes,1,opt,name=player,proto3" json:"player,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlayerRequest) Reset() {
+	*x = UpdatePlayerRequest{}
+	mi := &file_tictactoe_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlayerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlayerRequest) ProtoMessage() {}
+
+func (x *UpdatePlayerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlayerRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePlayerRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdatePlayerRequest) GetPlayer() *Player {
+	if x != nil {
+		return x.Player
+	}
+	return nil
+}
+
+type UpdatePlayerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePlayerResponse) Reset() {
+	*x = UpdatePlayerResponse{}
+	mi := &file_tictactoe_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePlayerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePlayerResponse) ProtoMessage() {}
+
+func (x *UpdatePlayerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePlayerResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePlayerResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{11}
+}
+
+type RestartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartRequest) Reset() {
+	*x = RestartRequest{}
+	mi := &file_tictactoe_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartRequest) ProtoMessage() {}
+
+func (x *RestartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartRequest.ProtoReflect.Descriptor instead.
+func (*RestartRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{12}
+}
+
+type RestartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartResponse) Reset() {
+	*x = RestartResponse{}
+	mi := &file_tictactoe_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartResponse) ProtoMessage() {}
+
+func (x *RestartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartResponse.ProtoReflect.Descriptor instead.
+func (*RestartResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{13}
+}
+
+type ClearRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearRequest) Reset() {
+	*x = ClearRequest{}
+	mi := &file_tictactoe_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearRequest) ProtoMessage() {}
+
+func (x *ClearRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearRequest.ProtoReflect.Descriptor instead.
+func (*ClearRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{14}
+}
+
+type ClearResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearResponse) Reset() {
+	*x = ClearResponse{}
+	mi := &file_tictactoe_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearResponse) ProtoMessage() {}
+
+func (x *ClearResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearResponse.ProtoReflect.Descriptor instead.
+func (*ClearResponse) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{15}
+}
+
+type WatchGameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchGameRequest) Reset() {
+	*x = WatchGameRequest{}
+	mi := &file_tictactoe_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchGameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchGameRequest) ProtoMessage() {}
+
+func (x *WatchGameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tictactoe_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchGameRequest.ProtoReflect.Descriptor instead.
+func (*WatchGameRequest) Descriptor() ([]byte, []int) {
+	return file_tictactoe_proto_rawDescGZIP(), []int{16}
+}
+
+var File_tictactoe_proto protoreflect.FileDescriptor
+
+const file_tictactoe_proto_rawDesc = "" +
+	"\n" +
+	"\x0ftictactoe.proto\x12\ttictactoe\x1a\x1fgoogle/protobuf/timestamp.proto\"L\n" +
+	"\x06Player\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x10\n" +
+	"\x03bot\x18\x03 \x01(\bR\x03botB\a\n" +
+	"\x05_name\"Q\n" +
+	"\x04Move\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\tR\bplayerId\x12\x15\n" +
+	"\x06x_axis\x18\x02 \x01(\x05R\x05xAxis\x12\x15\n" +
+	"\x06y_axis\x18\x03 \x01(\x05R\x05yAxis\"\x88\x03\n" +
+	"\x04Game\x12\x14\n" +
+	"\x05board\x18\x01 \x03(\x05R\x05board\x12'\n" +
+	"\x05queue\x18\x02 \x03(\v2\x11.tictactoe.PlayerR\x05queue\x12,\n" +
+	"\bplayer_x\x18\x03 \x01(\v2\x11.tictactoe.PlayerR\aplayerX\x12,\n" +
+	"\bplayer_o\x18\x04 \x01(\v2\x11.tictactoe.PlayerR\aplayerO\x12\x12\n" +
+	"\x04move\x18\x05 \x01(\tR\x04move\x12)\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x11.tictactoe.StatusR\x06status\x12)\n" +
+	"\ahistory\x18\a \x03(\v2\x0f.tictactoe.MoveR\ahistory\x12?\n" +
+	"\rmove_deadline\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\fmoveDeadline\x12\x16\n" +
+	"\x06paused\x18\t \x01(\bR\x06paused\x12\"\n" +
+	"\fannouncement\x18\n" +
+	" \x01(\tR\fannouncement\"\x10\n" +
+	"\x0eGetGameRequest\"2\n" +
+	"\vMoveRequest\x12#\n" +
+	"\x04move\x18\x01 \x01(\v2\x0f.tictactoe.MoveR\x04move\"\x0e\n" +
+	"\fMoveResponse\"=\n" +
+	"\x10SubscribeRequest\x12)\n" +
+	"\x06player\x18\x01 \x01(\v2\x11.tictactoe.PlayerR\x06player\"#\n" +
+	"\x11SubscribeResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"$\n" +
+	"\x12UnsubscribeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13UnsubscribeResponse\"@\n" +
+	"\x13UpdatePlayerRequest\x12)\n" +
+	"\x06player\x18\x01 \x01(\v2\x11.tictactoe.PlayerR\x06player\"\x16\n" +
+	"\x14UpdatePlayerResponse\"\x10\n" +
+	"\x0eRestartRequest\"\x11\n" +
+	"\x0fRestartResponse\"\x0e\n" +
+	"\fClearRequest\"\x0f\n" +
+	"\rClearResponse\"\x12\n" +
+	"\x10WatchGameRequest*{\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14INSUFFICIENT_PLAYERS\x10\x01\x12\f\n" +
+	"\bNO_BOARD\x10\x02\x12\n" +
+	"\n" +
+	"\x06X_WINS\x10\x03\x12\n" +
+	"\n" +
+	"\x06O_WINS\x10\x04\x12\b\n" +
+	"\x04CATS\x10\x05\x12\x0f\n" +
+	"\vIN_PROGRESS\x10\x062\x9d\x04\n" +
+	"\tTicTacToe\x125\n" +
+	"\aGetGame\x12\x19.tictactoe.GetGameRequest\x1a\x0f.tictactoe.Game\x127\n" +
+	"\x04Move\x12\x16.tictactoe.MoveRequest\x1a\x17.tictactoe.MoveResponse\x12F\n" +
+	"\tSubscribe\x12\x1b.tictactoe.SubscribeRequest\x1a\x1c.tictactoe.SubscribeResponse\x12L\n" +
+	"\vUnsubscribe\x12\x1d.tictactoe.UnsubscribeRequest\x1a\x1e.tictactoe.UnsubscribeResponse\x12O\n" +
+	"\fUpdatePlayer\x12\x1e.tictactoe.UpdatePlayerRequest\x1a\x1f.tictactoe.UpdatePlayerResponse\x12@\n" +
+	"\aRestart\x12\x19.tictactoe.RestartRequest\x1a\x1a.tictactoe.RestartResponse\x12:\n" +
+	"\x05Clear\x12\x17.tictactoe.ClearRequest\x1a\x18.tictactoe.ClearResponse\x12;\n" +
+	"\tWatchGame\x12\x1b.tictactoe.WatchGameRequest\x1a\x0f.tictactoe.Game0\x01B0Z.git.tmaws.io/nathan.hyland/tic_tac_toe/api;apib\x06proto3"
+
+var (
+	file_tictactoe_proto_rawDescOnce sync.Once
+	file_tictactoe_proto_rawDescData []byte
+)
+
+func file_tictactoe_proto_rawDescGZIP() []byte {
+	file_tictactoe_proto_rawDescOnce.Do(func() {
+		file_tictactoe_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tictactoe_proto_rawDesc), len(file_tictactoe_proto_rawDesc)))
+	})
+	return file_tictactoe_proto_rawDescData
+}
+
+var file_tictactoe_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tictactoe_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_tictactoe_proto_goTypes = []any{
+	(Status)(0),                   // 0: tictactoe.Status
+	(*Player)(nil),                // 1: tictactoe.Player
+	(*Move)(nil),                  // 2: tictactoe.Move
+	(*Game)(nil),                  // 3: tictactoe.Game
+	(*GetGameRequest)(nil),        // 4: tictactoe.GetGameRequest
+	(*MoveRequest)(nil),           // 5: tictactoe.MoveRequest
+	(*MoveResponse)(nil),          // 6: tictactoe.MoveResponse
+	(*SubscribeRequest)(nil),      // 7: tictactoe.SubscribeRequest
+	(*SubscribeResponse)(nil),     // 8: tictactoe.SubscribeResponse
+	(*UnsubscribeRequest)(nil),    // 9: tictactoe.UnsubscribeRequest
+	(*UnsubscribeResponse)(nil),   // 10: tictactoe.UnsubscribeResponse
+	(*UpdatePlayerRequest)(nil),   // 11: tictactoe.UpdatePlayerRequest
+	(*UpdatePlayerResponse)(nil),  // 12: tictactoe.UpdatePlayerResponse
+	(*RestartRequest)(nil),        // 13: tictactoe.RestartRequest
+	(*RestartResponse)(nil),       // 14: tictactoe.RestartResponse
+	(*ClearRequest)(nil),          // 15: tictactoe.ClearRequest
+	(*ClearResponse)(nil),         // 16: tictactoe.ClearResponse
+	(*WatchGameRequest)(nil),      // 17: tictactoe.WatchGameRequest
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+}
+var file_tictactoe_proto_depIdxs = []int32{
+	1,  // 0: tictactoe.Game.queue:type_name -> tictactoe.Player
+	1,  // 1: tictactoe.Game.player_x:type_name -> tictactoe.Player
+	1,  // 2: tictactoe.Game.player_o:type_name -> tictactoe.Player
+	0,  // 3: tictactoe.Game.status:type_name -> tictactoe.Status
+	2,  // 4: tictactoe.Game.history:type_name -> tictactoe.Move
+	18, // 5: tictactoe.Game.move_deadline:type_name -> google.protobuf.Timestamp
+	2,  // 6: tictactoe.MoveRequest.move:type_name -> tictactoe.Move
+	1,  // 7: tictactoe.SubscribeRequest.player:type_name -> tictactoe.Player
+	1,  // 8: tictactoe.UpdatePlayerRequest.player:type_name -> tictactoe.Player
+	4,  // 9: tictactoe.TicTacToe.GetGame:input_type -> tictactoe.GetGameRequest
+	5,  // 10: tictactoe.TicTacToe.Move:input_type -> tictactoe.MoveRequest
+	7,  // 11: tictactoe.TicTacToe.Subscribe:input_type -> tictactoe.SubscribeRequest
+	9,  // 12: tictactoe.TicTacToe.Unsubscribe:input_type -> tictactoe.UnsubscribeRequest
+	11, // 13: tictactoe.TicTacToe.UpdatePlayer:input_type -> tictactoe.UpdatePlayerRequest
+	13, // 14: tictactoe.TicTacToe.Restart:input_type -> tictactoe.RestartRequest
+	15, // 15: tictactoe.TicTacToe.Clear:input_type -> tictactoe.ClearRequest
+	17, // 16: tictactoe.TicTacToe.WatchGame:input_type -> tictactoe.WatchGameRequest
+	3,  // 17: tictactoe.TicTacToe.GetGame:output_type -> tictactoe.Game
+	6,  // 18: tictactoe.TicTacToe.Move:output_type -> tictactoe.MoveResponse
+	8,  // 19: tictactoe.TicTacToe.Subscribe:output_type -> tictactoe.SubscribeResponse
+	10, // 20: tictactoe.TicTacToe.Unsubscribe:output_type -> tictactoe.UnsubscribeResponse
+	12, // 21: tictactoe.TicTacToe.UpdatePlayer:output_type -> tictactoe.UpdatePlayerResponse
+	14, // 22: tictactoe.TicTacToe.Restart:output_type -> tictactoe.RestartResponse
+	16, // 23: tictactoe.TicTacToe.Clear:output_type -> tictactoe.ClearResponse
+	3,  // 24: tictactoe.TicTacToe.WatchGame:output_type -> tictactoe.Game
+	17, // [17:25] is the sub-list for method output_type
+	9,  // [9:17] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_tictactoe_proto_init() }
+func file_tictactoe_proto_init() {
+	if File_tictactoe_proto != nil {
+		return
+	}
+	file_tictactoe_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tictactoe_proto_rawDesc), len(file_tictactoe_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tictactoe_proto_goTypes,
+		DependencyIndexes: file_tictactoe_proto_depIdxs,
+		EnumInfos:         file_tictactoe_proto_enumTypes,
+		MessageInfos:      file_tictactoe_proto_msgTypes,
+	}.Build()
+	File_tictactoe_proto = out.File
+	file_tictactoe_proto_goTypes = nil
+	file_tictactoe_proto_depIdxs = nil
+}
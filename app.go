@@ -1,9 +1,18 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
@@ -15,15 +24,180 @@ func main() {
 	log := logger.New()
 	g := game.New(log.WithField("package", "game_engine"), 5*time.Second, nil)
 
-	r, err := Route(g, nil)
+	if fname, ok := os.LookupEnv("BLOCKED_WORDS_FILE"); ok {
+		if err := loadBlockedWords(g, fname); err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	shutdownTimeout := 10 * time.Second
+	if t, ok := os.LookupEnv("SHUTDOWN_TIMEOUT"); ok {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		shutdownTimeout = d
+	}
+
+	var engineCommands map[string]string
+	if fname, ok := os.LookupEnv("ENGINES_FILE"); ok {
+		cmds, err := loadEngineCommands(fname)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		engineCommands = cmds
+	}
+
+	var requireStore bool
+	if v, ok := os.LookupEnv("REQUIRE_STORE"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		requireStore = b
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h, err := NewHandler(g, nil, Config{
+		AdminToken:     os.Getenv("ADMIN_TOKEN"),
+		RequireStore:   requireStore,
+		Room:           os.Getenv("ROOM"),
+		EngineCommands: engineCommands,
+		Context:        ctx,
+	})
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	handler := cors.Default().Handler(r)
+	if sc, ok, err := storeConfig(); err != nil {
+		log.Fatalln(err)
+	} else if ok {
+		if err := h.InitStore(ctx, sc); err != nil {
+			log.Fatalln(err)
+		}
+		log.WithField("projectID", sc.ProjectID).Info("store initialized")
+	}
+
+	grpcPort := ":9090"
+	if p, ok := os.LookupEnv("GRPC_PORT"); ok {
+		grpcPort = fmt.Sprintf(":%s", p)
+	}
+	lis, err := net.Listen("tcp", grpcPort)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	grpcSrv := NewGRPCServer(g)
+
+	handler := cors.New(cors.Options{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Admin-Actor"},
+		ExposedHeaders: []string{"Deprecation", "Link"},
+	}).Handler(h.Router())
 	port := ":8080"
 	if p, ok := os.LookupEnv("PORT"); ok {
 		port = fmt.Sprintf(":%s", p)
 	}
-	log.Fatalln(http.ListenAndServe(port, handler))
+	srv := &http.Server{Addr: port, Handler: handler}
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- grpcSrv.Serve(lis) }()
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-signals:
+		log.WithField("signal", sig.String()).Info("shutting down")
+	case err := <-errCh:
+		log.WithError(err).Error("server stopped, shutting down")
+	}
+	signal.Stop(signals)
+
+	sctx, scancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer scancel()
+	if err := shutdown(sctx, srv, grpcSrv, h, g, cancel); err != nil {
+		log.WithError(err).Error("shutdown incomplete")
+		os.Exit(1)
+	}
+	log.Info("shut down")
+}
+
+// loadBlockedWords adds the words in fname, one per line, to the name
+// rules. Blank lines and lines starting with # are skipped.
+func loadBlockedWords(g *game.Game, fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rules := g.NameRules()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		rules.BlockedWords = append(rules.BlockedWords, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return g.SetNameRules(rules)
+}
+
+// loadEngineCommands reads the engines bots can use from fname, one per
+// line as a name then the command to run. Blank lines and lines starting
+// with # are skipped.
+func loadEngineCommands(fname string) (map[string]string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cmds := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s: engine %q needs a command", fname, fields[0])
+		}
+		cmds[fields[0]] = strings.Join(fields[1:], " ")
+	}
+	return cmds, scanner.Err()
+}
+
+// storeConfig reads the store settings from the environment. The store
+// is only set up at startup when FIREBASE_PROJECT_ID is set, with the
+// service account key in FIREBASE_CREDENTIALS or the file named by
+// FIREBASE_CREDENTIALS_FILE. FIREBASE_CREDENTIALS is unset once read so
+// the key isn't passed on to anything the server runs.
+func storeConfig() (StoreConfig, bool, error) {
+	projectID, ok := os.LookupEnv("FIREBASE_PROJECT_ID")
+	if !ok {
+		return StoreConfig{}, false, nil
+	}
+	sc := StoreConfig{ProjectID: projectID, Bucket: os.Getenv("FIREBASE_BUCKET")}
+
+	if creds, ok := os.LookupEnv("FIREBASE_CREDENTIALS"); ok {
+		sc.Credentials = []byte(creds)
+		os.Unsetenv("FIREBASE_CREDENTIALS")
+		return sc, true, nil
+	}
+	fname, ok := os.LookupEnv("FIREBASE_CREDENTIALS_FILE")
+	if !ok {
+		return sc, false, errors.New("need FIREBASE_CREDENTIALS or FIREBASE_CREDENTIALS_FILE with FIREBASE_PROJECT_ID")
+	}
+	bs, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return sc, false, err
+	}
+	sc.Credentials = bs
+	return sc, true, nil
 }
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/gorilla/mux"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// botRequestTimeout caps how long a bot is given to move when there
+	// is no deadline, like when the timeout is paused
+	botRequestTimeout = 10 * time.Second
+	// botTurnQueueSize is how many turns can wait to be sent to bots.
+	// Turns that don't fit are left to the timeout.
+	botTurnQueueSize = 16
+	// maxBotResponseSize bounds the move read back from a bot
+	maxBotResponseSize = 64 << 10
+	// botEngineStartTimeout is how long an engine command has to start
+	// and answer ttt
+	botEngineStartTimeout = 10 * time.Second
+)
+
+var (
+	errBotNotFound = errors.New("bot not found")
+	errBotExists   = errors.New("bot already registered")
+	errBotURL      = errors.New("bot url must be an absolute http or https url")
+	errBotTarget   = errors.New("bot needs either a url or an engine")
+	errBotEngine   = errors.New("unknown engine")
+	errBotMove     = errors.New("bot move is off the board")
+)
+
+// Bot is a player whose moves are asked of a URL or chosen by an engine.
+// On its turn the URL is sent a POST of the game and answers with the
+// move to make. Engines are the ones built into the game package, or
+// commands named in Config.EngineCommands, run with game.ProcessEngine.
+type Bot struct {
+	Player game.Player `json:"player"`
+	URL    string      `json:"url,omitempty"`
+	Engine string      `json:"engine,omitempty"`
+}
+
+// bots asks registered bots for their moves
+type bots struct {
+	sync.Mutex
+	// registered is in the order bots were added
+	registered []Bot
+
+	// engines of bots with an engine, by player id
+	engines map[string]game.Engine
+
+	game   *game.Game
+	turns  chan game.Event
+	client *http.Client
+	// commands are the engine commands bots can use, by name
+	commands map[string]string
+}
+
+func newBots(g *game.Game, commands map[string]string) *bots {
+	return &bots{
+		engines:  map[string]game.Engine{},
+		game:     g,
+		turns:    make(chan game.Event, botTurnQueueSize),
+		client:   &http.Client{},
+		commands: commands,
+	}
+}
+
+// startEngine starts the engine called name
+func (b *bots) startEngine(name string) (game.Engine, error) {
+	if e, ok := game.BuiltinEngine(name, time.Now().UnixNano()); ok {
+		return e, nil
+	}
+	args := strings.Fields(b.commands[name])
+	if len(args) == 0 {
+		return nil, errBotEngine
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), botEngineStartTimeout)
+	defer cancel()
+	return game.StartProcessEngine(ctx, args[0], args[1:]...)
+}
+
+// closeEngine stops e if it runs in another process
+func closeEngine(e game.Engine) {
+	if c, ok := e.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.WithError(err).Warn("engine did not exit cleanly")
+		}
+	}
+}
+
+// add registers a bot and adds it to the game like any other player
+func (b *bots) add(bot Bot) (Bot, error) {
+	var engine game.Engine
+	switch {
+	case (bot.URL == "") == (bot.Engine == ""):
+		return Bot{}, errBotTarget
+	case bot.Engine != "":
+		e, err := b.startEngine(bot.Engine)
+		if err != nil {
+			return Bot{}, err
+		}
+		engine = e
+	default:
+		u, ok := httpURL(bot.URL)
+		if !ok {
+			return Bot{}, errBotURL
+		}
+		bot.URL = u
+	}
+	if bot.Player.ID == "" {
+		bot.Player.ID = uuid.NewV4().String()
+	}
+	bot.Player.Bot = true
+
+	// registered first so the bot is asked to move if it starts a game
+	b.Lock()
+	for _, registered := range b.registered {
+		if registered.Player.ID == bot.Player.ID {
+			b.Unlock()
+			if engine != nil {
+				closeEngine(engine)
+			}
+			return Bot{}, errBotExists
+		}
+	}
+	b.registered = append(b.registered, bot)
+	if engine != nil {
+		b.engines[bot.Player.ID] = engine
+	}
+	b.Unlock()
+	if err := b.game.AddPlayer(bot.Player); err != nil {
+		b.forget(bot.Player.ID)
+		return Bot{}, err
+	}
+	return bot, nil
+}
+
+// remove takes a bot out of the game and stops asking it for moves
+func (b *bots) remove(id string) error {
+	if !b.forget(id) {
+		return errBotNotFound
+	}
+	if err := b.game.RemovePlayer(id); err != nil && err != game.ErrPlayerNotFound {
+		return err
+	}
+	return nil
+}
+
+// forget unregisters a bot and stops its engine, reporting whether it
+// was registered
+func (b *bots) forget(id string) bool {
+	b.Lock()
+	engine := b.engines[id]
+	delete(b.engines, id)
+	found := false
+	for i, bot := range b.registered {
+		if bot.Player.ID == id {
+			b.registered = append(b.registered[:i], b.registered[i+1:]...)
+			found = true
+			break
+		}
+	}
+	b.Unlock()
+
+	if engine != nil {
+		closeEngine(engine)
+	}
+	return found
+}
+
+// get returns the bot with id, and its engine if it has one
+func (b *bots) get(id string) (Bot, game.Engine, bool) {
+	b.Lock()
+	defer b.Unlock()
+	for _, bot := range b.registered {
+		if bot.Player.ID == id {
+			return bot, b.engines[id], true
+		}
+	}
+	return Bot{}, nil, false
+}
+
+// closeEngines stops the engines of every bot
+func (b *bots) closeEngines() {
+	b.Lock()
+	engines := b.engines
+	b.engines = map[string]game.Engine{}
+	b.Unlock()
+	for _, e := range engines {
+		closeEngine(e)
+	}
+}
+
+func (b *bots) list() []Bot {
+	b.Lock()
+	defer b.Unlock()
+	return append([]Bot{}, b.registered...)
+}
+
+// notify queues the turns of bots. It is called by the game, so it never
+// blocks.
+func (b *bots) notify(e game.Event) {
+	if e.Type != game.EventYourTurn || e.Player == nil || !e.Player.Bot {
+		return
+	}
+	select {
+	case b.turns <- e:
+	default:
+		log.WithField("player_id", e.Player.ID).Warn("too many bot turns waiting, leaving it to the timeout")
+	}
+}
+
+// run asks bots for their moves until ctx is done, then stops their
+// engines once the moves being asked for are done. Each turn is played
+// on its own goroutine, so a slow bot doesn't hold up the others.
+func (b *bots) run(ctx context.Context) {
+	go func() {
+		var wg sync.WaitGroup
+		for {
+			select {
+			case e := <-b.turns:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					b.play(ctx, e)
+				}()
+			case <-ctx.Done():
+				wg.Wait()
+				b.closeEngines()
+				return
+			}
+		}
+	}()
+}
+
+// play asks the bot to move for the turn e and makes its move. A bot that
+// fails, or answers after the deadline, has its move made by the timeout
+// like any other player.
+func (b *bots) play(ctx context.Context, e game.Event) {
+	logCtx := log.WithField("player_id", e.Player.ID)
+	bot, engine, ok := b.get(e.Player.ID)
+	if !ok {
+		logCtx.Warn("bot no longer registered")
+		return
+	}
+
+	deadline := time.Now().Add(botRequestTimeout)
+	if e.Deadline != nil && e.Deadline.Before(deadline) {
+		deadline = *e.Deadline
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	if engine != nil {
+		logCtx = logCtx.WithField("engine", bot.Engine)
+		p := game.EnginePlayer{Player: bot.Player, Engine: engine}
+		if err := p.Move(ctx, b.game); err != nil {
+			logCtx.WithError(err).Error("engine did not move, leaving it to the timeout")
+		}
+		return
+	}
+
+	logCtx = logCtx.WithField("url", bot.URL)
+	move, err := b.ask(ctx, bot)
+	if err != nil {
+		logCtx.WithError(err).Error("bot did not move, leaving it to the timeout")
+		return
+	}
+	move.PlayerID = bot.Player.ID
+	if err := b.game.PlacePiece(*move); err != nil {
+		logCtx.WithError(err).Error("bot move rejected, leaving it to the timeout")
+	}
+}
+
+// ask POSTs the game to the bot and reads back its move
+func (b *bots) ask(ctx context.Context, bot Bot) (*game.Move, error) {
+	state := b.game.State()
+	body, err := json.Marshal(&state)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, bot.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tic_tac_toe-bots")
+	req.Header.Set("X-Bot-Player-ID", bot.Player.ID)
+
+	res, err := b.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxBotResponseSize))
+		return nil, fmt.Errorf("bot returned %s", res.Status)
+	}
+
+	var move game.Move
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxBotResponseSize)).Decode(&move); err != nil {
+		return nil, err
+	}
+	if move.XAxis < 0 || move.XAxis >= len(state.Board[0]) || move.YAxis < 0 || move.YAxis >= len(state.Board) {
+		return nil, errBotMove
+	}
+	return &move, nil
+}
+
+// AdminAddBot adds a bot player to the game. Takes a body of
+// {"player": {"id": string, "name": string}, "url": string} or with
+// "engine": string instead of url, the id is made up when empty.
+func (h *Handler) AdminAddBot(w http.ResponseWriter, r *http.Request) {
+	var bot Bot
+	if err := json.NewDecoder(r.Body).Decode(&bot); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	added, err := h.bots.add(bot)
+	if err != nil {
+		h.audit(r, "add_bot", bot.Player.ID, bot.URL+bot.Engine, err)
+		writePlayerError(w, err)
+		return
+	}
+	h.audit(r, "add_bot", added.Player.ID, added.URL+added.Engine, nil)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(added)
+}
+
+// AdminBots lists the bots, in the order they were added
+func (h *Handler) AdminBots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.bots.list())
+}
+
+// AdminRemoveBot takes a bot out of the game
+func (h *Handler) AdminRemoveBot(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.writeAdmin(w, r, "remove_bot", id, "", h.bots.remove(id))
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+// AnalysisRequest is a position of any variant to search. The variant
+// defaults to 3,3,3, the board to empty and the side to whoever's turn it
+// is by the pieces on the board.
+type AnalysisRequest struct {
+	game.Position
+	// Iterations and MoveTimeMS limit the search. The server caps both,
+	// and makes 10000 playouts when neither is set.
+	Iterations int `json:"iterations,omitempty"`
+	MoveTimeMS int `json:"movetime_ms,omitempty"`
+}
+
+// Analysis is what the server's MCTS engine found out about each move
+type Analysis struct {
+	Variant game.Variant `json:"variant"`
+	Side    string       `json:"side"`
+	// Moves are the empty squares within two squares of a piece, the
+	// most tried first
+	Moves []game.MoveStat `json:"moves"`
+}
+
+// Analyze searches a position with the server's MCTS engine
+func (c *Client) Analyze(ctx context.Context, req AnalysisRequest) (*Analysis, error) {
+	var a Analysis
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/analysis", req, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
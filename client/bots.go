@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+// Bot is a player whose moves are asked of URL, or chosen by Engine
+type Bot struct {
+	Player game.Player `json:"player"`
+	URL    string      `json:"url,omitempty"`
+	Engine string      `json:"engine,omitempty"`
+}
+
+// AddBot adds a bot player to the game or the queue, making up its id
+// when it is empty. On its turn u is sent a POST of the game and must
+// answer with a move before the deadline. Needs AdminToken.
+func (c *Client) AddBot(ctx context.Context, p game.Player, u string) (*Bot, error) {
+	var bot Bot
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/admin/bots", Bot{Player: p, URL: u}, &bot); err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// AddEngineBot adds a bot player whose moves are chosen by the server's
+// engine called engine: first, random, minimax, mcts or one of the
+// commands it was configured with. Needs AdminToken.
+func (c *Client) AddEngineBot(ctx context.Context, p game.Player, engine string) (*Bot, error) {
+	var bot Bot
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/admin/bots", Bot{Player: p, Engine: engine}, &bot); err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// Bots lists the bots, in the order they were added. Needs AdminToken.
+func (c *Client) Bots(ctx context.Context) ([]Bot, error) {
+	var bots []Bot
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/bots", nil, &bots); err != nil {
+		return nil, err
+	}
+	return bots, nil
+}
+
+// RemoveBot takes a bot out of the game. Needs AdminToken.
+func (c *Client) RemoveBot(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/admin/bots/"+url.PathEscape(id), nil, nil)
+}
@@ -0,0 +1,405 @@
+// Package client is a Go client for the HTTP API described in
+// api/openapi.json.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+// Error is returned when the server responds with an unexpected status.
+// Code is set for errors the server gives a code, like players rejected
+// by moderation.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Replay is a recorded game with every board position, starting with the
+// empty board
+type Replay struct {
+	game.Record
+	Positions []game.Board `json:"positions"`
+}
+
+// Position is the board of a recorded game after Ply moves
+type Position struct {
+	ID    string      `json:"id"`
+	Ply   int         `json:"ply"`
+	Plies int         `json:"plies"`
+	Board *game.Board `json:"board"`
+}
+
+// AuditEntry is a record of an admin action
+type AuditEntry struct {
+	Date       time.Time `json:"date"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	Target     string    `json:"target,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Client calls a game server
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	// AdminToken is sent as a bearer token, it is only needed for the
+	// admin methods
+	AdminToken string
+}
+
+// New returns a client for the server at baseURL, like http://localhost:8080
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HTTP:    http.DefaultClient,
+	}
+}
+
+// do sends a request and returns the response if it has the expected
+// status code. The caller must close the body.
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, expected int) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AdminToken)
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != expected {
+		defer res.Body.Close()
+		bs, _ := ioutil.ReadAll(res.Body)
+		e := &Error{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(bs))}
+		if strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+			// a coded error, keep the raw body if it is not one
+			json.Unmarshal(bs, e)
+		}
+		return nil, e
+	}
+	return res, nil
+}
+
+// doJSON sends in as JSON, if not nil, and decodes the response into out,
+// if not nil
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		bs, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body, contentType = bytes.NewReader(bs), "application/json"
+	}
+
+	res, err := c.do(ctx, method, path, contentType, body, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *Client) bytes(ctx context.Context, path string) ([]byte, error) {
+	res, err := c.do(ctx, http.MethodGet, path, "", nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return ioutil.ReadAll(res.Body)
+}
+
+// gamePath is the path of a recorded game followed by suffix
+func gamePath(id, suffix string) string {
+	return "/v1/games/" + url.PathEscape(id) + suffix
+}
+
+// Game gets the game status
+func (c *Client) Game(ctx context.Context) (*game.Game, error) {
+	var g game.Game
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/game", nil, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Move places a piece for the player whose turn it is
+func (c *Client) Move(ctx context.Context, m game.Move) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/game/moves", m, nil)
+}
+
+// Subscribe adds p to the game or the queue and returns their id, which
+// the server assigns if p.ID is empty
+func (c *Client) Subscribe(ctx context.Context, p game.Player) (string, error) {
+	var res struct {
+		ID string `json:"id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/players", p, &res); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// Unsubscribe removes the player with id from the game or the queue
+func (c *Client) Unsubscribe(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/players/"+url.PathEscape(id), nil, nil)
+}
+
+// UpdatePlayer updates a registered player
+func (c *Client) UpdatePlayer(ctx context.Context, p game.Player) error {
+	return c.doJSON(ctx, http.MethodPut, "/v1/players/"+url.PathEscape(p.ID), p, nil)
+}
+
+// Restart clears the board and starts the game again with the same
+// players
+func (c *Client) Restart(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/game/restart", nil, nil)
+}
+
+// Clear resets the game, removing the players and the queue
+func (c *Client) Clear(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/game", nil, nil)
+}
+
+// Init sets up the Firebase store with a service account key. Needs
+// AdminToken.
+func (c *Client) Init(ctx context.Context, projectID, bucket string, credentials []byte) error {
+	path := fmt.Sprintf("/v1/init/project/%s/bucket/%s", url.PathEscape(projectID), url.PathEscape(bucket))
+	res, err := c.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(credentials), http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Games lists recorded games, oldest first
+func (c *Client) Games(ctx context.Context) ([]game.Record, error) {
+	var records []game.Record
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/games", nil, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Replay gets a recorded game with every board position
+func (c *Client) Replay(ctx context.Context, id string) (*Replay, error) {
+	var r Replay
+	if err := c.doJSON(ctx, http.MethodGet, gamePath(id, "/replay"), nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ReplayPly gets the board of a recorded game after ply moves
+func (c *Client) ReplayPly(ctx context.Context, id string, ply int) (*Position, error) {
+	var p Position
+	path := gamePath(id, "/replay/"+strconv.Itoa(ply))
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Notation exports a recorded game in notation format
+func (c *Client) Notation(ctx context.Context, id string) (string, error) {
+	bs, err := c.bytes(ctx, gamePath(id, "/notation"))
+	return string(bs), err
+}
+
+// Import imports a game in notation format as a recorded game
+func (c *Client) Import(ctx context.Context, notation string) (*Replay, error) {
+	res, err := c.do(ctx, http.MethodPost, "/v1/games", "text/plain", strings.NewReader(notation), http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var r Replay
+	if err := json.NewDecoder(res.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// BoardImage renders the current board, format is svg or png
+func (c *Client) BoardImage(ctx context.Context, format string) ([]byte, error) {
+	return c.bytes(ctx, "/v1/game/board."+format)
+}
+
+// RecordImage renders a recorded game after ply moves, format is svg or
+// png. A negative ply renders the final position.
+func (c *Client) RecordImage(ctx context.Context, id, format string, ply int) ([]byte, error) {
+	path := gamePath(id, "/board."+format)
+	if ply >= 0 {
+		path += "?ply=" + strconv.Itoa(ply)
+	}
+	return c.bytes(ctx, path)
+}
+
+// ReplayGIF gets an animated GIF of a recorded game
+func (c *Client) ReplayGIF(ctx context.Context, id string) ([]byte, error) {
+	return c.bytes(ctx, gamePath(id, "/replay.gif"))
+}
+
+// Kick removes a player from the game or the queue. Needs AdminToken.
+func (c *Client) Kick(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/players/"+url.PathEscape(id)+"/kick", nil, nil)
+}
+
+// Ban removes a player and stops them subscribing again. Needs AdminToken.
+func (c *Client) Ban(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/players/"+url.PathEscape(id)+"/ban", nil, nil)
+}
+
+// Bans lists the ids of banned players. Needs AdminToken.
+func (c *Client) Bans(ctx context.Context) ([]string, error) {
+	var ids []string
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/bans", nil, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Unban lets a banned player subscribe again. Needs AdminToken.
+func (c *Client) Unban(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/admin/bans/"+url.PathEscape(id), nil, nil)
+}
+
+// NameRules gets the rules for player names. Needs AdminToken.
+func (c *Client) NameRules(ctx context.Context) (*game.NameRules, error) {
+	var r game.NameRules
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/name-rules", nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SetNameRules replaces the rules for player names. Needs AdminToken.
+func (c *Client) SetNameRules(ctx context.Context, r game.NameRules) error {
+	return c.doJSON(ctx, http.MethodPut, "/v1/admin/name-rules", r, nil)
+}
+
+// MoveInQueue moves a queued player to pos, where 0 is next to play.
+// Needs AdminToken.
+func (c *Client) MoveInQueue(ctx context.Context, id string, pos int) error {
+	in := struct {
+		Position int `json:"position"`
+	}{pos}
+	return c.doJSON(ctx, http.MethodPut, "/v1/admin/queue/"+url.PathEscape(id), in, nil)
+}
+
+// ForceResult ends the game in progress with result. Needs AdminToken.
+func (c *Client) ForceResult(ctx context.Context, result game.Status) error {
+	in := struct {
+		Result game.Status `json:"result"`
+	}{result}
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/game/result", in, nil)
+}
+
+// PauseTimeout stops the move timeout. Needs AdminToken.
+func (c *Client) PauseTimeout(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/game/pause", nil, nil)
+}
+
+// ResumeTimeout restarts the move timeout. Needs AdminToken.
+func (c *Client) ResumeTimeout(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/game/resume", nil, nil)
+}
+
+// Broadcast sends message to everyone following the game. Needs
+// AdminToken.
+func (c *Client) Broadcast(ctx context.Context, message string) error {
+	in := struct {
+		Message string `json:"message"`
+	}{message}
+	return c.doJSON(ctx, http.MethodPost, "/v1/admin/broadcast", in, nil)
+}
+
+// Audit lists admin actions, oldest first. Needs AdminToken.
+func (c *Client) Audit(ctx context.Context) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/audit", nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Events follows the game status until ctx is done or the connection
+// drops, at which point the returned channel is closed
+func (c *Client) Events(ctx context.Context) (<-chan game.Game, error) {
+	res, err := c.do(ctx, http.MethodGet, "/v1/game/events", "", nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	games := make(chan game.Game)
+	go func() {
+		defer close(games)
+		defer res.Body.Close()
+		// a closed channel is how callers learn the stream ended
+		readEvents(ctx, res.Body, games)
+	}()
+	return games, nil
+}
+
+// readEvents decodes game events from a server sent event stream
+func readEvents(ctx context.Context, r io.Reader, games chan<- game.Game) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	event, data := "", ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == "game" && data != "" {
+				var g game.Game
+				if err := json.Unmarshal([]byte(data), &g); err != nil {
+					return err
+				}
+				select {
+				case games <- g:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	return scanner.Err()
+}
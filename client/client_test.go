@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		"event: game",
+		`data: {"status":"InsufficientPlayers"}`,
+		"",
+		": comments and other events are skipped",
+		"event: ping",
+		"data: {}",
+		"",
+		"event: game",
+		`data: {"status":"InProgress","move":"X"}`,
+		"",
+		"",
+	}, "\n")
+
+	games := make(chan game.Game, 2)
+	assert.NoError(t, readEvents(context.Background(), strings.NewReader(stream), games))
+	close(games)
+
+	var statuses []game.Status
+	for g := range games {
+		statuses = append(statuses, g.Status)
+	}
+	assert.Equal(t, []game.Status{game.InsufficientPlayers, game.InProgress}, statuses)
+}
+
+func TestError(t *testing.T) {
+	err := &Error{StatusCode: 404, Message: "game record not found"}
+	assert.Equal(t, "404 Not Found: game record not found", err.Error())
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	body := `{"id":"1","type":"ping","date":"2026-01-01T00:00:00Z"}`
+	sign := func(secret, timestamp string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "." + body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tCases := []struct {
+		name      string
+		timestamp string
+		signature string
+		err       error
+	}{
+		{name: "accepts a fresh delivery signed with the secret", timestamp: now, signature: sign("secret", now)},
+		{name: "rejects another secret", timestamp: now, signature: sign("other", now), err: ErrBadSignature},
+		{name: "rejects an old delivery", timestamp: old, signature: sign("secret", old), err: ErrBadSignature},
+		{name: "rejects a changed timestamp", timestamp: now, signature: sign("secret", old), err: ErrBadSignature},
+		{name: "rejects a missing signature", timestamp: now, err: ErrBadSignature},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+			r.Header.Set("X-Webhook-Timestamp", tc.timestamp)
+			r.Header.Set("X-Webhook-Signature", tc.signature)
+
+			d, err := VerifyWebhook(r, "secret", time.Minute)
+			assert.Equal(t, tc.err, err)
+			if err == nil {
+				assert.Equal(t, "1", d.ID)
+				assert.Equal(t, game.EventType("ping"), d.Type)
+			}
+		})
+	}
+}
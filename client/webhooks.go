@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+// ErrBadSignature is returned by VerifyWebhook for deliveries that were
+// not signed with the secret, or were signed too long ago
+var ErrBadSignature = errors.New("bad webhook signature")
+
+// Webhook is a URL sent a signed POST for each of its events
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Room is the room whose events are sent, or empty for every room
+	Room   string           `json:"room,omitempty"`
+	Events []game.EventType `json:"events"`
+	// Secret is only returned by AddWebhook
+	Secret  string    `json:"secret,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// WebhookDelivery is the body POSTed to a webhook. ID is the same for
+// every attempt.
+type WebhookDelivery struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	game.Event
+}
+
+// WebhookResult is the outcome of TestWebhook
+type WebhookResult struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DeadLetter is a delivery that failed every attempt
+type DeadLetter struct {
+	WebhookID string          `json:"webhook_id"`
+	URL       string          `json:"url"`
+	Delivery  WebhookDelivery `json:"delivery"`
+	Attempts  int             `json:"attempts"`
+	Error     string          `json:"error"`
+	Date      time.Time       `json:"date"`
+}
+
+// AddWebhook registers u to be sent events, or every event when there are
+// none, in every room. The returned webhook has the secret to check
+// deliveries with. Needs AdminToken.
+func (c *Client) AddWebhook(ctx context.Context, u string, events ...game.EventType) (*Webhook, error) {
+	return c.AddRoomWebhook(ctx, u, "", events...)
+}
+
+// AddRoomWebhook is AddWebhook for the events of one room
+func (c *Client) AddRoomWebhook(ctx context.Context, u, room string, events ...game.EventType) (*Webhook, error) {
+	bs, err := json.Marshal(struct {
+		URL    string           `json:"url"`
+		Room   string           `json:"room,omitempty"`
+		Events []game.EventType `json:"events,omitempty"`
+	}{u, room, events})
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(ctx, http.MethodPost, "/v1/admin/webhooks", "application/json", bytes.NewReader(bs), http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var hook Webhook
+	if err := json.NewDecoder(res.Body).Decode(&hook); err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// Webhooks lists the webhooks, without their secrets. Needs AdminToken.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var hooks []Webhook
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/webhooks", nil, &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// RemoveWebhook stops sending events to a webhook. Needs AdminToken.
+func (c *Client) RemoveWebhook(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodDelete, "/v1/admin/webhooks/"+url.PathEscape(id), nil, nil)
+}
+
+// TestWebhook has the server send a ping to a webhook straight away. An
+// *Error with status 502 means the webhook did not accept it. Needs
+// AdminToken.
+func (c *Client) TestWebhook(ctx context.Context, id string) (*WebhookResult, error) {
+	var res WebhookResult
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/admin/webhooks/"+url.PathEscape(id)+"/test", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DeadLetters lists deliveries that failed every attempt, oldest first.
+// Needs AdminToken.
+func (c *Client) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	var letters []DeadLetter
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/admin/webhooks/dead-letters", nil, &letters); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// VerifyWebhook checks a delivery received by a webhook was signed with
+// secret no more than maxAge ago, and returns it
+func VerifyWebhook(r *http.Request, secret string, maxAge time.Duration) (*WebhookDelivery, error) {
+	bs, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	timestamp := r.Header.Get("X-Webhook-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrBadSignature
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > maxAge || age < -maxAge {
+		return nil, ErrBadSignature
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get("X-Webhook-Signature"), "sha256="))
+	if err != nil {
+		return nil, ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(bs)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, ErrBadSignature
+	}
+
+	var d WebhookDelivery
+	if err := json.Unmarshal(bs, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,220 @@
+// Command arena plays engines against each other offline and reports how
+// they did.
+//
+//	arena -a minimax -b random -games 1000
+//	arena -a minimax -b "python3 mybot.py"
+//
+// Engines are random, first (the first open square, as played for
+// players who time out), minimax, mcts (Monte Carlo tree search), or a
+// command run as an external engine speaking the protocol described by
+// game.ProcessEngine. The engines swap sides every game and X always
+// moves first.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+// startTimeout is how long engine commands have to start and answer ttt
+const startTimeout = 10 * time.Second
+
+// player is an engine taking part, and how it has done
+type player struct {
+	name   string
+	engine game.Engine
+	close  func() error
+
+	// results as X and as O
+	wins, draws, losses [2]int
+	forfeits            int
+}
+
+// newPlayer loads the engine named by spec. seed seeds random engines.
+func newPlayer(spec string, seed int64) (*player, error) {
+	p := &player{name: spec, close: func() error { return nil }}
+	if e, ok := game.BuiltinEngine(spec, seed); ok {
+		p.engine = e
+		return p, nil
+	}
+
+	args := strings.Fields(spec)
+	if len(args) == 0 {
+		return nil, errors.New("need an engine")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	e, err := game.StartProcessEngine(ctx, args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", spec, err)
+	}
+	p.name, p.engine, p.close = e.Name(), e, e.Close
+	return p, nil
+}
+
+// play plays a game between x and o, giving each moveTime per move. An
+// engine that errors, is too slow or plays an illegal move forfeits.
+func play(x, o *player, moveTime time.Duration) (game.Status, *player) {
+	for _, p := range []*player{x, o} {
+		if ng, ok := p.engine.(game.NewGamer); ok {
+			if err := ng.NewGame(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", p.name, err)
+			}
+		}
+	}
+
+	var bb game.Bitboard
+	side, mover, other := "X", x, o
+	for bb.Status() == game.InProgress {
+		ctx, cancel := context.WithTimeout(context.Background(), moveTime)
+		move, err := mover.engine.BestMove(ctx, bb, side)
+		cancel()
+		if err == nil {
+			bb, err = bb.Play(side, move)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s forfeits as %s: %s\n", mover.name, side, err)
+			if side == "X" {
+				return game.OWins, mover
+			}
+			return game.XWins, mover
+		}
+
+		if side == "X" {
+			side = "O"
+		} else {
+			side = "X"
+		}
+		mover, other = other, mover
+	}
+	return bb.Status(), nil
+}
+
+// record counts the result of a game for the engine that played side,
+// 0 for X and 1 for O
+func (p *player) record(side int, result game.Status) {
+	switch {
+	case result == game.Cats:
+		p.draws[side]++
+	case (result == game.XWins) == (side == 0):
+		p.wins[side]++
+	default:
+		p.losses[side]++
+	}
+}
+
+func (p *player) games() int {
+	return p.wins[0] + p.wins[1] + p.draws[0] + p.draws[1] + p.losses[0] + p.losses[1]
+}
+
+// score is the share of points won, counting a draw as half a win
+func (p *player) score() float64 {
+	points := float64(p.wins[0]+p.wins[1]) + float64(p.draws[0]+p.draws[1])/2
+	return points / float64(p.games())
+}
+
+// eloDiff is the rating difference that predicts score, infinite for a
+// clean sweep either way, as no difference predicts one
+func eloDiff(score float64) float64 {
+	return 400 * math.Log10(score/(1-score))
+}
+
+// eloMargin is the 95% confidence interval of the Elo difference of p
+func (p *player) eloMargin() (lo, hi float64) {
+	n := float64(p.games())
+	mean := p.score()
+	variance := (float64(p.wins[0]+p.wins[1])*math.Pow(1-mean, 2) +
+		float64(p.draws[0]+p.draws[1])*math.Pow(0.5-mean, 2) +
+		float64(p.losses[0]+p.losses[1])*math.Pow(mean, 2)) / n
+	margin := 1.96 * math.Sqrt(variance/n)
+	return eloDiff(math.Max(mean-margin, 0)), eloDiff(math.Min(mean+margin, 1))
+}
+
+// eloBound writes one end of a confidence interval, which has no bound
+// when the interval reaches a score of 0 or 1
+func eloBound(d float64) string {
+	if math.IsInf(d, 0) {
+		return "unbounded"
+	}
+	return fmt.Sprintf("%+.0f", d)
+}
+
+// match plays games between a and b, a playing X in even games, and
+// records the results
+func match(a, b *player, games int, moveTime time.Duration) {
+	for i := 0; i < games; i++ {
+		x, o := a, b
+		if i%2 == 1 {
+			x, o = b, a
+		}
+		result, forfeited := play(x, o, moveTime)
+		if forfeited != nil {
+			forfeited.forfeits++
+		}
+		x.record(0, result)
+		o.record(1, result)
+	}
+}
+
+func report(w io.Writer, a, b *player) {
+	fmt.Fprintf(w, "%s vs %s, %d games\n\n", a.name, b.name, a.games())
+	fmt.Fprintf(w, "%-8s %8s %8s %8s\n", "", "wins", "draws", "losses")
+	for i, side := range []string{"as X", "as O"} {
+		fmt.Fprintf(w, "%-8s %8d %8d %8d\n", side, a.wins[i], a.draws[i], a.losses[i])
+	}
+	fmt.Fprintf(w, "%-8s %8d %8d %8d\n\n",
+		"total", a.wins[0]+a.wins[1], a.draws[0]+a.draws[1], a.losses[0]+a.losses[1])
+
+	if diff := eloDiff(a.score()); math.IsInf(diff, 0) {
+		fmt.Fprintf(w, "score %.1f%%, elo difference: no finite estimate from a clean sweep\n", 100*a.score())
+	} else {
+		lo, hi := a.eloMargin()
+		fmt.Fprintf(w, "score %.1f%%, elo difference %+.0f (95%%: %s to %s)\n",
+			100*a.score(), diff, eloBound(lo), eloBound(hi))
+	}
+	if a.forfeits > 0 || b.forfeits > 0 {
+		fmt.Fprintf(w, "forfeits: %s %d, %s %d\n", a.name, a.forfeits, b.name, b.forfeits)
+	}
+}
+
+func main() {
+	aSpec := flag.String("a", "minimax", "first engine: random, first, minimax, mcts or a command")
+	bSpec := flag.String("b", "random", "second engine: random, first, minimax, mcts or a command")
+	games := flag.Int("games", 1000, "number of games to play")
+	moveTime := flag.Duration("movetime", time.Second, "time each engine has per move")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for random engines")
+	flag.Parse()
+	if *games < 1 {
+		fmt.Fprintln(os.Stderr, "need at least one game")
+		os.Exit(2)
+	}
+
+	a, err := newPlayer(*aSpec, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer a.close()
+	b, err := newPlayer(*bSpec, *seed+1)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		a.close()
+		os.Exit(1)
+	}
+	defer b.close()
+	if a.name == b.name {
+		a.name, b.name = a.name+" (a)", b.name+" (b)"
+	}
+
+	match(a, b, *games, *moveTime)
+	report(os.Stdout, a, b)
+}
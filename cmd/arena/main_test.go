@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubEngine plays the same move, or returns the same error, every time
+type stubEngine struct {
+	move game.Move
+	err  error
+}
+
+func (e stubEngine) BestMove(context.Context, game.Bitboard, string) (game.Move, error) {
+	return e.move, e.err
+}
+
+// slowEngine thinks until it runs out of time
+type slowEngine struct{}
+
+func (slowEngine) BestMove(ctx context.Context, _ game.Bitboard, _ string) (game.Move, error) {
+	<-ctx.Done()
+	return game.Move{}, ctx.Err()
+}
+
+func builtin(t *testing.T, name string) *player {
+	p, err := newPlayer(name, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewPlayer(t *testing.T) {
+	p, err := newPlayer("minimax", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "minimax", p.name)
+	assert.NoError(t, p.close())
+
+	_, err = newPlayer("", 1)
+	assert.Error(t, err)
+	_, err = newPlayer("./no-such-engine", 1)
+	assert.Error(t, err)
+}
+
+func TestPlay(t *testing.T) {
+	tCases := []struct {
+		name      string
+		x, o      game.Engine
+		result    game.Status
+		forfeited string
+	}{
+		{
+			// X takes the top row before O can stop it
+			name:   "first open against itself",
+			x:      game.FirstOpenEngine{},
+			o:      game.FirstOpenEngine{},
+			result: game.XWins,
+		},
+		{
+			name:   "perfect play draws",
+			x:      game.NewMinimaxEngine(),
+			o:      game.NewMinimaxEngine(),
+			result: game.Cats,
+		},
+		{
+			name:      "an engine error forfeits",
+			x:         stubEngine{err: errors.New("crashed")},
+			o:         game.FirstOpenEngine{},
+			result:    game.OWins,
+			forfeited: "x",
+		},
+		{
+			name:      "an illegal move forfeits",
+			x:         game.FirstOpenEngine{},
+			o:         stubEngine{move: game.Move{XAxis: 0, YAxis: 0}},
+			result:    game.XWins,
+			forfeited: "o",
+		},
+		{
+			name:      "a move off the board forfeits",
+			x:         stubEngine{move: game.Move{XAxis: 3, YAxis: 0}},
+			o:         game.FirstOpenEngine{},
+			result:    game.OWins,
+			forfeited: "x",
+		},
+		{
+			name:      "running out of time forfeits",
+			x:         game.FirstOpenEngine{},
+			o:         slowEngine{},
+			result:    game.XWins,
+			forfeited: "o",
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			x := &player{name: "x", engine: tc.x}
+			o := &player{name: "o", engine: tc.o}
+			result, forfeited := play(x, o, 10*time.Millisecond)
+			assert.Equal(t, tc.result, result)
+			if tc.forfeited == "" {
+				assert.Nil(t, forfeited)
+			} else if assert.NotNil(t, forfeited) {
+				assert.Equal(t, tc.forfeited, forfeited.name)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tCases := []struct {
+		name                string
+		side                int
+		result              game.Status
+		wins, draws, losses [2]int
+	}{
+		{name: "win as X", side: 0, result: game.XWins, wins: [2]int{1, 0}},
+		{name: "win as O", side: 1, result: game.OWins, wins: [2]int{0, 1}},
+		{name: "loss as X", side: 0, result: game.OWins, losses: [2]int{1, 0}},
+		{name: "loss as O", side: 1, result: game.XWins, losses: [2]int{0, 1}},
+		{name: "draw as X", side: 0, result: game.Cats, draws: [2]int{1, 0}},
+		{name: "draw as O", side: 1, result: game.Cats, draws: [2]int{0, 1}},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var p player
+			p.record(tc.side, tc.result)
+			assert.Equal(t, tc.wins, p.wins)
+			assert.Equal(t, tc.draws, p.draws)
+			assert.Equal(t, tc.losses, p.losses)
+			assert.Equal(t, 1, p.games())
+		})
+	}
+}
+
+func TestMatchSwapsSides(t *testing.T) {
+	// the first open engine wins as X and loses as O against itself
+	a, b := builtin(t, "first"), builtin(t, "first")
+	match(a, b, 5, time.Second)
+
+	assert.Equal(t, [2]int{3, 0}, a.wins)
+	assert.Equal(t, [2]int{0, 2}, a.losses)
+	assert.Equal(t, [2]int{2, 0}, b.wins)
+	assert.Equal(t, [2]int{0, 3}, b.losses)
+	assert.Zero(t, a.forfeits+b.forfeits)
+}
+
+func TestMatchCountsForfeits(t *testing.T) {
+	a := builtin(t, "first")
+	b := &player{name: "crashing", engine: stubEngine{err: errors.New("crashed")}}
+	match(a, b, 4, time.Second)
+
+	assert.Equal(t, [2]int{2, 2}, a.wins)
+	assert.Equal(t, [2]int{2, 2}, b.losses)
+	assert.Equal(t, 0, a.forfeits)
+	assert.Equal(t, 4, b.forfeits)
+}
+
+func TestScore(t *testing.T) {
+	p := player{wins: [2]int{2, 1}, draws: [2]int{1, 1}, losses: [2]int{0, 3}}
+	assert.Equal(t, 8, p.games())
+	assert.InDelta(t, 0.5, p.score(), 1e-9)
+
+	p = player{draws: [2]int{3, 3}}
+	assert.InDelta(t, 0.5, p.score(), 1e-9)
+}
+
+func TestEloDiff(t *testing.T) {
+	tCases := []struct {
+		score    float64
+		expected float64
+	}{
+		{score: 0.5, expected: 0},
+		{score: 0.75, expected: 400 * math.Log10(3)},
+		{score: 0.25, expected: -400 * math.Log10(3)},
+		{score: 10.0 / 11, expected: 400},
+	}
+	for _, tc := range tCases {
+		assert.InDelta(t, tc.expected, eloDiff(tc.score), 1e-9, "score %v", tc.score)
+	}
+	assert.True(t, math.IsInf(eloDiff(1), 1), "expected a clean sweep to have no finite difference")
+	assert.True(t, math.IsInf(eloDiff(0), -1), "expected a clean sweep to have no finite difference")
+}
+
+func TestEloMargin(t *testing.T) {
+	// draws only, so there is no doubt about the difference
+	p := player{draws: [2]int{5, 5}}
+	lo, hi := p.eloMargin()
+	assert.InDelta(t, 0, lo, 1e-9)
+	assert.InDelta(t, 0, hi, 1e-9)
+
+	p = player{wins: [2]int{30, 30}, draws: [2]int{10, 10}, losses: [2]int{10, 10}}
+	lo, hi = p.eloMargin()
+	diff := eloDiff(p.score())
+	assert.True(t, lo < diff && diff < hi, "expected %v to lie between %v and %v", diff, lo, hi)
+
+	// one loss in many games leaves the top of the interval at a sweep
+	p = player{wins: [2]int{2, 2}, losses: [2]int{1, 0}}
+	_, hi = p.eloMargin()
+	assert.True(t, math.IsInf(hi, 1))
+}
+
+func TestReport(t *testing.T) {
+	tCases := []struct {
+		name     string
+		a, b     player
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "even",
+			a:    player{name: "a", wins: [2]int{2, 1}, losses: [2]int{1, 2}},
+			b:    player{name: "b", wins: [2]int{2, 1}, losses: [2]int{1, 2}},
+			contains: []string{
+				"a vs b, 6 games",
+				"as X            2        0        1",
+				"score 50.0%, elo difference +0 (95%: ",
+			},
+			excludes: []string{"forfeits"},
+		},
+		{
+			name: "sweep",
+			a:    player{name: "a", wins: [2]int{2, 2}},
+			b:    player{name: "b", losses: [2]int{2, 2}, forfeits: 1},
+			contains: []string{
+				"score 100.0%, elo difference: no finite estimate",
+				"forfeits: a 0, b 1",
+			},
+			excludes: []string{"Inf", "NaN"},
+		},
+		{
+			name:     "swept",
+			a:        player{name: "a", losses: [2]int{2, 2}},
+			b:        player{name: "b", wins: [2]int{2, 2}},
+			contains: []string{"score 0.0%, elo difference: no finite estimate"},
+			excludes: []string{"Inf", "NaN"},
+		},
+		{
+			name:     "interval reaching a sweep",
+			a:        player{name: "a", wins: [2]int{2, 2}, losses: [2]int{1, 0}},
+			b:        player{name: "b", wins: [2]int{0, 1}, losses: [2]int{2, 2}},
+			contains: []string{"to unbounded)"},
+			excludes: []string{"Inf", "NaN"},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			report(&buf, &tc.a, &tc.b)
+			for _, s := range tc.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tc.excludes {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Command ttt plays tic tac toe against a server from the terminal.
+//
+//	ttt -server http://localhost:8080 -name nat
+//
+// Moves are typed as a square, like b2. Type q to leave the game. With
+// -tui the client takes over the terminal and moves are made with the
+// arrow keys.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/client"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+)
+
+func playerName(p *game.Player, me string) string {
+	if p == nil {
+		return "(waiting)"
+	}
+	name := p.ID
+	if p.Name != nil && *p.Name != "" {
+		name = *p.Name
+	}
+	if p.ID == me {
+		name += " (you)"
+	}
+	return name
+}
+
+// turnID returns the id of the player to move, else an empty string
+func turnID(g *game.Game) string {
+	if g.Status != game.InProgress {
+		return ""
+	}
+	switch {
+	case g.Move == "X" && g.X != nil:
+		return g.X.ID
+	case g.Move == "O" && g.O != nil:
+		return g.O.ID
+	}
+	return ""
+}
+
+// render writes the board and game status as seen by the player me
+func render(w io.Writer, g *game.Game, me string) {
+	fmt.Fprintf(w, "\nX: %s\nO: %s\n\n", playerName(g.X, me), playerName(g.O, me))
+	fmt.Fprintln(w, "    a   b   c")
+	for y := 0; y < 3; y++ {
+		cells := make([]string, 3)
+		for x := range cells {
+			cells[x] = " "
+			if g.Board == nil {
+				continue
+			}
+			switch g.Board[y][x] {
+			case -1:
+				cells[x] = "X"
+			case 1:
+				cells[x] = "O"
+			}
+		}
+		fmt.Fprintf(w, "%d   %s\n", y+1, strings.Join(cells, " | "))
+		if y < 2 {
+			fmt.Fprintln(w, "   ---+---+---")
+		}
+	}
+	fmt.Fprintln(w)
+
+	switch g.Status {
+	case game.InProgress:
+		turn := fmt.Sprintf("%s to move", g.Move)
+		if turnID(g) == me {
+			turn = fmt.Sprintf("your move (%s)", g.Move)
+		}
+		if g.Deadline != nil {
+			left := time.Until(*g.Deadline).Round(time.Second)
+			if left < 0 {
+				left = 0
+			}
+			turn += fmt.Sprintf(", %s left", left)
+		}
+		fmt.Fprintln(w, turn)
+	case game.XWins, game.OWins, game.Cats:
+		fmt.Fprintf(w, "game over: %s\n", g.Status)
+	default:
+		fmt.Fprintf(w, "waiting: %s\n", g.Status)
+	}
+
+	for i, p := range g.Queue {
+		if p.ID == me {
+			fmt.Fprintf(w, "you are #%d in the queue\n", i+1)
+		}
+	}
+}
+
+// snapshot is what has to change for the board to be drawn again
+func snapshot(g *game.Game) string {
+	bs, _ := json.Marshal(struct {
+		Board  *game.Board
+		X, O   *game.Player
+		Queue  []game.Player
+		Move   string
+		Status game.Status
+	}{g.Board, g.X, g.O, g.Queue, g.Move, g.Status})
+	return string(bs)
+}
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "game server address")
+	name := flag.String("name", "", "display name")
+	id := flag.String("id", "", "player id, assigned by the server if empty")
+	poll := flag.Duration("poll", 500*time.Millisecond, "how often to poll for updates")
+	fullScreen := flag.Bool("tui", false, "use the full screen interface")
+	flag.Parse()
+
+	c := client.New(*server)
+	c.HTTP = &http.Client{Timeout: 10 * time.Second}
+	ctx := context.Background()
+	p := game.Player{ID: *id}
+	if *name != "" {
+		p.Name = name
+	}
+
+	me, err := c.Subscribe(ctx, p)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("subscribed as %s\n", me)
+
+	if *fullScreen {
+		err := runTUI(c, me, *poll)
+		if uerr := c.Unsubscribe(ctx, me); uerr != nil {
+			fmt.Fprintln(os.Stderr, uerr)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- strings.TrimSpace(scanner.Text())
+		}
+		close(lines)
+	}()
+
+	tick := time.NewTicker(*poll)
+	defer tick.Stop()
+	play(ctx, c, me, lines, tick.C, os.Stdout, os.Stderr)
+	if err := c.Unsubscribe(ctx, me); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// play makes the moves typed on lines and polls the game on each tick,
+// drawing it to w when it changes, until lines ends or q is typed.
+// Errors polling the game go to errW.
+func play(ctx context.Context, c *client.Client, me string, lines <-chan string, tick <-chan time.Time, w, errW io.Writer) {
+	var (
+		g    *game.Game
+		last string
+	)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok || line == "q" {
+				return
+			}
+			if line == "" {
+				continue
+			}
+			x, y, err := game.ParseSquare(line)
+			if err != nil {
+				fmt.Fprintln(w, "moves look like b2, q to quit")
+				continue
+			}
+			if g == nil || turnID(g) != me {
+				fmt.Fprintln(w, "not your turn")
+				continue
+			}
+			if err := c.Move(ctx, game.Move{PlayerID: me, XAxis: x, YAxis: y}); err != nil {
+				fmt.Fprintln(w, err)
+			}
+
+		case <-tick:
+			next, err := c.Game(ctx)
+			if err != nil {
+				fmt.Fprintln(errW, err)
+				continue
+			}
+			g = next
+			if s := snapshot(g); s != last {
+				last = s
+				render(w, g, me)
+			}
+		}
+	}
+}
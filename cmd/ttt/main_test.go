@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/client"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// inProgress is a game with me as X and them as O, with move to play
+func inProgress(move string) *game.Game {
+	return &game.Game{
+		Board:  &game.Board{},
+		X:      &game.Player{ID: "me"},
+		O:      &game.Player{ID: "them", Name: strPtr("Nat")},
+		Move:   move,
+		Status: game.InProgress,
+	}
+}
+
+func TestTurnID(t *testing.T) {
+	over := inProgress("X")
+	over.Status = game.XWins
+	noO := inProgress("O")
+	noO.O = nil
+
+	tCases := []struct {
+		name     string
+		g        *game.Game
+		expected string
+	}{
+		{name: "X to move", g: inProgress("X"), expected: "me"},
+		{name: "O to move", g: inProgress("O"), expected: "them"},
+		{name: "game over", g: over},
+		{name: "player missing", g: noO},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, turnID(tc.g))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	played := inProgress("O")
+	played.Board[0][0] = -1
+	played.Board[1][2] = 1
+	passed := time.Now().Add(-time.Minute)
+	late := inProgress("X")
+	late.Deadline = &passed
+	over := inProgress("X")
+	over.Status = game.OWins
+	queued := &game.Game{
+		X:      &game.Player{ID: "them"},
+		Queue:  []game.Player{{ID: "other"}, {ID: "me"}},
+		Status: game.InsufficientPlayers,
+	}
+
+	tCases := []struct {
+		name     string
+		g        *game.Game
+		contains []string
+	}{
+		{
+			name: "your move",
+			g:    inProgress("X"),
+			contains: []string{
+				"X: me (you)\nO: Nat\n",
+				"    a   b   c\n1     |   |  \n   ---+---+---\n",
+				"your move (X)\n",
+			},
+		},
+		{
+			name:     "pieces on the board",
+			g:        played,
+			contains: []string{"1   X |   |  \n", "2     |   | O\n", "O to move\n"},
+		},
+		{
+			name:     "deadline passed",
+			g:        late,
+			contains: []string{"your move (X), 0s left\n"},
+		},
+		{
+			name:     "game over",
+			g:        over,
+			contains: []string{"game over: OWins\n"},
+		},
+		{
+			name:     "waiting in the queue",
+			g:        queued,
+			contains: []string{"X: them\nO: (waiting)\n", "waiting: InsufficientPlayers\n", "you are #2 in the queue\n"},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			render(&b, tc.g, "me")
+			for _, s := range tc.contains {
+				assert.Contains(t, b.String(), s)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	deadline := time.Now()
+	tCases := []struct {
+		name   string
+		change func(g *game.Game)
+		same   bool
+	}{
+		{name: "unchanged", change: func(g *game.Game) {}, same: true},
+		{name: "deadline", change: func(g *game.Game) { g.Deadline = &deadline }, same: true},
+		{name: "history", change: func(g *game.Game) { g.History = []game.Move{{PlayerID: "me"}} }, same: true},
+		{name: "board", change: func(g *game.Game) { g.Board[1][1] = -1 }},
+		{name: "player", change: func(g *game.Game) { g.O = &game.Player{ID: "other"} }},
+		{name: "queue", change: func(g *game.Game) { g.Queue = []game.Player{{ID: "other"}} }},
+		{name: "move", change: func(g *game.Game) { g.Move = "O" }},
+		{name: "status", change: func(g *game.Game) { g.Status = game.Cats }},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := inProgress("X")
+			before := snapshot(g)
+			tc.change(g)
+			assert.Equal(t, tc.same, before == snapshot(g))
+		})
+	}
+}
+
+// fakeServer serves g as the current game and keeps the moves posted to it
+type fakeServer struct {
+	sync.Mutex
+	g     *game.Game
+	fail  bool
+	moves []game.Move
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	switch {
+	case s.fail:
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/game":
+		json.NewEncoder(w).Encode(s.g)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/game/moves":
+		var m game.Move
+		json.NewDecoder(r.Body).Decode(&m)
+		s.moves = append(s.moves, m)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	tCases := []struct {
+		name  string
+		g     *game.Game
+		fail  bool
+		ticks int
+		lines []string
+		// draws is how many times the board is drawn
+		draws int
+		out   string
+		moves []game.Move
+	}{
+		{
+			name:  "draws the game once while it is unchanged",
+			g:     inProgress("X"),
+			ticks: 3,
+			draws: 1,
+		},
+		{
+			name:  "moves on your turn",
+			g:     inProgress("X"),
+			ticks: 1,
+			lines: []string{"", "b2"},
+			draws: 1,
+			moves: []game.Move{{PlayerID: "me", XAxis: 1, YAxis: 1}},
+		},
+		{
+			name:  "not your turn",
+			g:     inProgress("O"),
+			ticks: 1,
+			lines: []string{"b2"},
+			draws: 1,
+			out:   "not your turn\n",
+		},
+		{
+			name:  "no game yet",
+			g:     inProgress("X"),
+			lines: []string{"b2"},
+			out:   "not your turn\n",
+		},
+		{
+			name:  "not a square",
+			g:     inProgress("X"),
+			ticks: 1,
+			lines: []string{"z9"},
+			draws: 1,
+			out:   "moves look like b2, q to quit\n",
+		},
+		{
+			name:  "stops on q",
+			g:     inProgress("X"),
+			ticks: 1,
+			lines: []string{"q", "b2"},
+			draws: 1,
+		},
+		{
+			name:  "server failing",
+			g:     inProgress("X"),
+			fail:  true,
+			ticks: 1,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &fakeServer{g: tc.g, fail: tc.fail}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			lines := make(chan string)
+			tick := make(chan time.Time)
+			done := make(chan struct{})
+			var out, errOut bytes.Buffer
+			go func() {
+				defer close(done)
+				play(context.Background(), client.New(srv.URL), "me", lines, tick, &out, &errOut)
+			}()
+
+			// play handles one at a time, so each send waits for the last
+			for i := 0; i < tc.ticks; i++ {
+				tick <- time.Now()
+			}
+			for _, l := range tc.lines {
+				select {
+				case lines <- l:
+				case <-done:
+				}
+			}
+			select {
+			case lines <- "":
+				close(lines)
+			case <-done:
+			}
+			<-done
+
+			assert.Equal(t, tc.draws, strings.Count(out.String(), "    a   b   c\n"))
+			if tc.out != "" {
+				assert.Contains(t, out.String(), tc.out)
+			}
+			assert.Equal(t, tc.fail, errOut.Len() > 0, "errors: %q", errOut.String())
+			assert.Equal(t, tc.moves, s.moves)
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/client"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// maxEvents is how many lines of the event log are shown
+const maxEvents = 8
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPlace
+	keyQuit
+)
+
+// readKeys turns raw terminal input into keys
+func readKeys(r io.Reader, keys chan<- key) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		in := buf[:n]
+		for len(in) > 0 {
+			k := keyNone
+			switch {
+			case bytes.HasPrefix(in, []byte("\x1b[A")):
+				k, in = keyUp, in[3:]
+			case bytes.HasPrefix(in, []byte("\x1b[B")):
+				k, in = keyDown, in[3:]
+			case bytes.HasPrefix(in, []byte("\x1b[C")):
+				k, in = keyRight, in[3:]
+			case bytes.HasPrefix(in, []byte("\x1b[D")):
+				k, in = keyLeft, in[3:]
+			default:
+				switch in[0] {
+				case 'k', 'w':
+					k = keyUp
+				case 'j', 's':
+					k = keyDown
+				case 'l', 'd':
+					k = keyRight
+				case 'h', 'a':
+					k = keyLeft
+				case ' ', '\r', '\n':
+					k = keyPlace
+				case 'q', 3: // 3 is ctrl-c
+					k = keyQuit
+				}
+				in = in[1:]
+			}
+			if k != keyNone {
+				keys <- k
+			}
+		}
+	}
+}
+
+type tui struct {
+	c      *client.Client
+	me     string
+	g      *game.Game
+	cx, cy int
+	events []string
+	errMsg string
+}
+
+func (t *tui) logEvent(format string, args ...interface{}) {
+	e := time.Now().Format("15:04:05 ") + fmt.Sprintf(format, args...)
+	t.events = append(t.events, e)
+	if len(t.events) > maxEvents {
+		t.events = t.events[len(t.events)-maxEvents:]
+	}
+}
+
+func playerID(p *game.Player) string {
+	if p == nil {
+		return ""
+	}
+	return p.ID
+}
+
+// update replaces the current state with next, logging what changed
+func (t *tui) update(next *game.Game) {
+	prev := t.g
+	t.g = next
+	if prev == nil {
+		t.logEvent("connected, status %s", next.Status)
+		return
+	}
+
+	if id := playerID(next.X); id != playerID(prev.X) && id != "" {
+		t.logEvent("%s plays X", playerName(next.X, t.me))
+	}
+	if id := playerID(next.O); id != playerID(prev.O) && id != "" {
+		t.logEvent("%s plays O", playerName(next.O, t.me))
+	}
+	if len(next.History) > len(prev.History) {
+		for _, m := range next.History[len(prev.History):] {
+			piece := "X"
+			if next.O != nil && m.PlayerID == next.O.ID {
+				piece = "O"
+			}
+			t.logEvent("%s played %s", piece, m.Square())
+		}
+	}
+	if next.Status != prev.Status {
+		switch next.Status {
+		case game.XWins, game.OWins, game.Cats:
+			t.logEvent("game over: %s", next.Status)
+		default:
+			t.logEvent("status %s", next.Status)
+		}
+	}
+	if len(next.Queue) != len(prev.Queue) {
+		t.logEvent("%d waiting in queue", len(next.Queue))
+	}
+}
+
+// place tries to move on the square under the cursor
+func (t *tui) place() {
+	if t.g == nil || turnID(t.g) != t.me {
+		t.errMsg = "not your turn"
+		return
+	}
+	if err := t.c.Move(context.Background(), game.Move{PlayerID: t.me, XAxis: t.cx, YAxis: t.cy}); err != nil {
+		t.errMsg = err.Error()
+		return
+	}
+	t.errMsg = ""
+}
+
+// draw renders the whole screen. The terminal is in raw mode so every
+// line ends in \r\n.
+func (t *tui) draw(w io.Writer) {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\x1b[K\r\n")
+	}
+
+	b.WriteString("\x1b[H")
+	line("tic tac toe  (arrows/hjkl move, space places, q quits)")
+	line("")
+	if t.g == nil {
+		line("connecting...")
+		b.WriteString("\x1b[J")
+		io.WriteString(w, b.String())
+		return
+	}
+	g := t.g
+
+	line("X: %s", playerName(g.X, t.me))
+	line("O: %s", playerName(g.O, t.me))
+	line("")
+	for y := 0; y < 3; y++ {
+		cells := make([]string, 3)
+		for x := range cells {
+			c := " "
+			if g.Board != nil {
+				switch g.Board[y][x] {
+				case -1:
+					c = "X"
+				case 1:
+					c = "O"
+				}
+			}
+			if x == t.cx && y == t.cy {
+				cells[x] = "\x1b[7m " + c + " \x1b[0m"
+			} else {
+				cells[x] = " " + c + " "
+			}
+		}
+		line("  %s", strings.Join(cells, "|"))
+		if y < 2 {
+			line("  ---+---+---")
+		}
+	}
+	line("")
+
+	switch g.Status {
+	case game.InProgress:
+		turn := fmt.Sprintf("%s to move", g.Move)
+		if turnID(g) == t.me {
+			turn = fmt.Sprintf("\x1b[1myour move (%s)\x1b[0m", g.Move)
+		}
+		if g.Deadline != nil {
+			left := time.Until(*g.Deadline)
+			if left < 0 {
+				left = 0
+			}
+			turn += fmt.Sprintf("  %.1fs", left.Seconds())
+		}
+		line("%s", turn)
+	case game.XWins, game.OWins, game.Cats:
+		line("game over: %s", g.Status)
+	default:
+		line("waiting: %s", g.Status)
+	}
+	line("%s", t.errMsg)
+	line("")
+
+	line("queue:")
+	if len(g.Queue) == 0 {
+		line("  (empty)")
+	}
+	for i := range g.Queue {
+		line("  %d. %s", i+1, playerName(&g.Queue[i], t.me))
+	}
+	line("")
+
+	line("events:")
+	for _, e := range t.events {
+		line("  %s", e)
+	}
+	b.WriteString("\x1b[J")
+	io.WriteString(w, b.String())
+}
+
+// handleKey moves the cursor or places a piece for k, and reports
+// whether the player quit
+func (t *tui) handleKey(k key) bool {
+	switch k {
+	case keyUp:
+		t.cy = (t.cy + 2) % 3
+	case keyDown:
+		t.cy = (t.cy + 1) % 3
+	case keyLeft:
+		t.cx = (t.cx + 2) % 3
+	case keyRight:
+		t.cx = (t.cx + 1) % 3
+	case keyPlace:
+		t.place()
+	case keyQuit:
+		return true
+	}
+	return false
+}
+
+// poll fetches the game and updates the screen's state with it
+func (t *tui) poll() {
+	g, err := t.c.Game(context.Background())
+	if err != nil {
+		t.errMsg = err.Error()
+		return
+	}
+	t.update(g)
+}
+
+// run handles keys and polls the game on each tick of poll, drawing the
+// screen to w after each and on each tick of redraw, until keys ends or
+// the player quits
+func (t *tui) run(keys <-chan key, poll, redraw <-chan time.Time, w io.Writer) {
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok || t.handleKey(k) {
+				return
+			}
+		case <-poll:
+			t.poll()
+		case <-redraw:
+		}
+		t.draw(w)
+	}
+}
+
+// runTUI takes over the terminal until the player quits
+func runTUI(c *client.Client, me string, poll time.Duration) error {
+	fd := int(os.Stdin.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore(fd, state)
+
+	// alternate screen, hidden cursor
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan key)
+	go readKeys(os.Stdin, keys)
+
+	t := &tui{c: c, me: me, cx: 1, cy: 1}
+	pollTick := time.NewTicker(poll)
+	defer pollTick.Stop()
+	drawTick := time.NewTicker(100 * time.Millisecond)
+	defer drawTick.Stop()
+
+	t.run(keys, pollTick.C, drawTick.C, os.Stdout)
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/client"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKeys(t *testing.T) {
+	tCases := []struct {
+		name     string
+		in       string
+		expected []key
+	}{
+		{name: "arrows", in: "\x1b[A\x1b[B\x1b[C\x1b[D", expected: []key{keyUp, keyDown, keyRight, keyLeft}},
+		{name: "vi keys", in: "kjlh", expected: []key{keyUp, keyDown, keyRight, keyLeft}},
+		{name: "wasd", in: "wsda", expected: []key{keyUp, keyDown, keyRight, keyLeft}},
+		{name: "place", in: " \r\n", expected: []key{keyPlace, keyPlace, keyPlace}},
+		{name: "quit", in: "q\x03", expected: []key{keyQuit, keyQuit}},
+		{name: "other keys are ignored", in: "x1\x1b[Ak", expected: []key{keyUp, keyUp}},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys := make(chan key)
+			go readKeys(strings.NewReader(tc.in), keys)
+			var actual []key
+			for k := range keys {
+				actual = append(actual, k)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	tCases := []struct {
+		name   string
+		keys   []key
+		cx, cy int
+		quit   bool
+	}{
+		{name: "up", keys: []key{keyUp}, cx: 1, cy: 0},
+		{name: "down", keys: []key{keyDown}, cx: 1, cy: 2},
+		{name: "left", keys: []key{keyLeft}, cx: 0, cy: 1},
+		{name: "right", keys: []key{keyRight}, cx: 2, cy: 1},
+		{name: "wraps around", keys: []key{keyUp, keyUp, keyRight, keyRight}, cx: 0, cy: 2},
+		{name: "quit", keys: []key{keyQuit}, cx: 1, cy: 1, quit: true},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &tui{me: "me", cx: 1, cy: 1}
+			quit := false
+			for _, k := range tc.keys {
+				quit = ui.handleKey(k)
+			}
+			assert.Equal(t, tc.quit, quit)
+			assert.Equal(t, tc.cx, ui.cx)
+			assert.Equal(t, tc.cy, ui.cy)
+		})
+	}
+}
+
+func TestHandleKeyPlace(t *testing.T) {
+	tCases := []struct {
+		name   string
+		g      *game.Game
+		errMsg string
+		moves  []game.Move
+	}{
+		{name: "your turn", g: inProgress("X"), moves: []game.Move{{PlayerID: "me", XAxis: 2, YAxis: 0}}},
+		{name: "not your turn", g: inProgress("O"), errMsg: "not your turn"},
+		{name: "no game yet", errMsg: "not your turn"},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &fakeServer{g: tc.g}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			ui := &tui{c: client.New(srv.URL), me: "me", g: tc.g, cx: 2, cy: 0}
+			assert.False(t, ui.handleKey(keyPlace))
+			assert.Equal(t, tc.errMsg, ui.errMsg)
+			assert.Equal(t, tc.moves, s.moves)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	joined := inProgress("X")
+	joined.O = nil
+	joined.Status = game.InsufficientPlayers
+	moved := inProgress("O")
+	moved.Board[1][1] = -1
+	moved.History = []game.Move{{PlayerID: "me", XAxis: 1, YAxis: 1}}
+	won := inProgress("O")
+	won.Status = game.XWins
+	queued := inProgress("X")
+	queued.Queue = []game.Player{{ID: "other"}}
+
+	tCases := []struct {
+		name     string
+		prev     *game.Game
+		next     *game.Game
+		expected []string
+	}{
+		{name: "first state", next: inProgress("X"), expected: []string{"connected, status InProgress"}},
+		{name: "unchanged", prev: inProgress("X"), next: inProgress("X")},
+		{name: "player joins", prev: joined, next: inProgress("X"), expected: []string{"Nat plays O", "status InProgress"}},
+		{name: "move", prev: inProgress("X"), next: moved, expected: []string{"X played b2"}},
+		{name: "game over", prev: inProgress("X"), next: won, expected: []string{"game over: XWins"}},
+		{name: "queue", prev: inProgress("X"), next: queued, expected: []string{"1 waiting in queue"}},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &tui{me: "me", g: tc.prev}
+			ui.update(tc.next)
+			assert.Equal(t, tc.next, ui.g)
+			if assert.Len(t, ui.events, len(tc.expected)) {
+				for i, e := range tc.expected {
+					assert.True(t, strings.HasSuffix(ui.events[i], " "+e), "expected %q to end in %q", ui.events[i], e)
+				}
+			}
+		})
+	}
+}
+
+func TestLogEventKeepsTheLatest(t *testing.T) {
+	ui := &tui{}
+	for i := 0; i < maxEvents+3; i++ {
+		ui.logEvent("event %d", i)
+	}
+	if assert.Len(t, ui.events, maxEvents) {
+		assert.True(t, strings.HasSuffix(ui.events[0], " event 3"))
+	}
+}
+
+func TestDraw(t *testing.T) {
+	played := inProgress("X")
+	played.Board[0][0] = -1
+	played.Board[1][1] = 1
+	queued := inProgress("O")
+	queued.Queue = []game.Player{{ID: "other", Name: strPtr("Sam")}}
+
+	tCases := []struct {
+		name     string
+		ui       *tui
+		contains []string
+	}{
+		{
+			name:     "connecting",
+			ui:       &tui{me: "me"},
+			contains: []string{"connecting...\x1b[K\r\n\x1b[J"},
+		},
+		{
+			name: "your move",
+			ui:   &tui{me: "me", g: played, cx: 1, cy: 1},
+			contains: []string{
+				"X: me (you)\x1b[K\r\n",
+				"O: Nat\x1b[K\r\n",
+				"   X |   |   \x1b[K\r\n",
+				"     |\x1b[7m O \x1b[0m|   \x1b[K\r\n",
+				"\x1b[1myour move (X)\x1b[0m\x1b[K\r\n",
+				"  (empty)\x1b[K\r\n",
+			},
+		},
+		{
+			name: "queue, error and events",
+			ui:   &tui{me: "me", g: queued, errMsg: "not your turn", events: []string{"12:00:00 X played b2"}},
+			contains: []string{
+				"O to move\x1b[K\r\nnot your turn\x1b[K\r\n",
+				"  1. Sam\x1b[K\r\n",
+				"events:\x1b[K\r\n  12:00:00 X played b2\x1b[K\r\n",
+			},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			tc.ui.draw(&b)
+			assert.True(t, strings.HasPrefix(b.String(), "\x1b[H"), "expected the screen drawn from the top")
+			for _, s := range tc.contains {
+				assert.Contains(t, b.String(), s)
+			}
+		})
+	}
+}
+
+func TestTUIRun(t *testing.T) {
+	s := &fakeServer{g: inProgress("X")}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	keys := make(chan key)
+	poll := make(chan time.Time)
+	redraw := make(chan time.Time)
+	done := make(chan struct{})
+	var out bytes.Buffer
+	ui := &tui{c: client.New(srv.URL), me: "me", cx: 1, cy: 1}
+	go func() {
+		defer close(done)
+		ui.run(keys, poll, redraw, &out)
+	}()
+
+	// run handles one at a time, so each send waits for the last
+	poll <- time.Now()
+	keys <- keyRight
+	keys <- keyPlace
+	redraw <- time.Now()
+	keys <- keyQuit
+	<-done
+
+	assert.Equal(t, 4, strings.Count(out.String(), "\x1b[H"), "expected a draw after each but quit")
+	assert.Contains(t, out.String(), "connected, status InProgress")
+	assert.Equal(t, []game.Move{{PlayerID: "me", XAxis: 2, YAxis: 1}}, s.moves)
+}
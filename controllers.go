@@ -1,25 +1,63 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	firebase "firebase.google.com/go"
 	"firebase.google.com/go/db"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/api"
 	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/render"
 	"github.com/gorilla/mux"
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/api/option"
 )
 
+// Config holds the settings of the HTTP API
+type Config struct {
+	// AdminToken authorizes requests to /v1/admin as a bearer token. The
+	// admin API is disabled when it is empty.
+	AdminToken string
+	// RequireStore makes the server not ready until Init has set up the
+	// store
+	RequireStore bool
+	// EngineCommands are the commands bots can be given as their engine,
+	// by name, as well as the engines built into the game package. Each
+	// bot runs its own process of the command.
+	EngineCommands map[string]string
+	// Room names the game the server hosts. Webhooks registered for
+	// another room are not sent its events. It defaults to "main".
+	Room string
+	// WebhookBackoff is the wait before retrying a webhook delivery,
+	// doubled for each retry after. It defaults to a second.
+	WebhookBackoff time.Duration
+	// Context ends background work, like updating the store, when it is
+	// done. It defaults to context.Background().
+	Context context.Context
+}
+
 type Handler struct {
-	game  *game.Game
-	store *db.Ref
+	game *game.Game
+	// storeMu guards store, which is set by InitStore
+	storeMu  sync.Mutex
+	store    *db.Ref
+	schema   *graphql.Schema
+	cfg      Config
+	auditLog auditLog
+	metrics  *metrics
+	webhooks *webhooks
+	bots     *bots
 }
 
 func (h *Handler) GetGame(w http.ResponseWriter, r *http.Request) {
@@ -27,7 +65,7 @@ func (h *Handler) GetGame(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Clear(w http.ResponseWriter, r *http.Request) {
-	h.game.Clear()
+	h.game.Reset()
 }
 
 func (h *Handler) Move(w http.ResponseWriter, r *http.Request) {
@@ -47,6 +85,8 @@ func (h *Handler) Move(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// UpdatePlayer updates a registered player. The ID comes from the path
+// if there is one, else the body.
 func (h *Handler) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 	var player game.Player
 	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
@@ -54,10 +94,12 @@ func (h *Handler) UpdatePlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	if id, ok := mux.Vars(r)["id"]; ok {
+		player.ID = id
+	}
 
 	if err := h.game.UpdatePlayer(player); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writePlayerError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -75,19 +117,43 @@ func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		id := uuid.NewV4()
 		player.ID = id.String()
 	}
+	// bots are added with the admin API
+	player.Bot = false
 
 	if err := h.game.AddPlayer(player); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		writePlayerError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(fmt.Sprintf(`{"id": "%s"}`, player.ID)))
 }
 
+// writePlayerError writes an error from adding or updating a player.
+// Players rejected by moderation get a JSON body with a code to match on.
+func writePlayerError(w http.ResponseWriter, err error) {
+	merr, ok := err.(*game.ModerationError)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	status := http.StatusUnprocessableEntity
+	if merr == game.ErrPlayerBanned {
+		status = http.StatusForbidden
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(merr)
+}
+
+// Unsubscribe removes a player. The ID comes from the path if there is
+// one, else the body.
 func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 	var player game.Player
-	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+	if id, ok := mux.Vars(r)["id"]; ok {
+		player.ID = id
+	} else if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -102,101 +168,484 @@ func (h *Handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Restart(w http.ResponseWriter, _ *http.Request) {
-	h.game.Clear()
-	h.game.NextGame()
+	h.game.Restart()
 	w.WriteHeader(http.StatusOK)
 }
 
-// Init initiates the game server with credentials from the user
-func (h *Handler) Init(w http.ResponseWriter, r *http.Request) {
-	bs, err := ioutil.ReadAll(r.Body)
+// Events streams the game state as server sent events, starting with
+// the current state and sending a new event after every change. A
+// goodbye event ends the stream when the server shuts down.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("streaming unsupported"))
+		return
+	}
+
+	updates, stop := h.game.Watch()
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(g *game.Game) error {
+		bs, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: game\ndata: %s\n\n", bs); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	if err := send(h.game); err != nil {
+		log.WithError(err).Error("error sending event")
+		return
+	}
+	for {
+		select {
+		case g, ok := <-updates:
+			if !ok {
+				// the game is closed for shutdown
+				fmt.Fprint(w, "event: goodbye\ndata: server shutting down\n\n")
+				flusher.Flush()
+				return
+			}
+			if err := send(&g); err != nil {
+				log.WithError(err).Error("error sending event")
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// OpenAPI serves the specification of this API
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.OpenAPI)
+}
+
+// Games lists all recorded games
+func (h *Handler) Games(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(h.game.Records())
+}
+
+// Replay returns every board state of a recorded game
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.game.Record(mux.Vars(r)["id"])
 	if err != nil {
-		log.WithError(err).Error("error reading body")
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(err.Error()))
+		return
 	}
 
-	vars := mux.Vars(r)
-	projectID, bucket := vars["projectID"], vars["bucket"]
+	positions, err := rec.Positions()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
 
-	log.WithFields(log.Fields{
-		"projectID": projectID,
-		"bucket":    bucket,
-	}).Info("initializing...")
+	json.NewEncoder(w).Encode(struct {
+		*game.Record
+		Positions []game.Board `json:"positions"`
+	}{rec, positions})
+}
 
-	cfg := firebase.Config{
-		DatabaseURL:   fmt.Sprintf("https://%s.firebaseio.com", projectID),
-		ProjectID:     projectID,
-		StorageBucket: bucket,
+// ReplayPly returns the board of a recorded game after a number of moves
+func (h *Handler) ReplayPly(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rec, err := h.game.Record(vars["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+		return
 	}
 
-	err = ioutil.WriteFile("credentials.json", bs, 0644)
+	ply, err := strconv.Atoi(vars["ply"])
 	if err != nil {
-		log.WithError(err).Error("unable to create credentials file")
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
+		return
 	}
 
-	db, err := database(context.Background(), cfg, "credentials.json")
+	board, err := rec.Position(ply)
 	if err != nil {
-		log.WithError(err).Error("unable to create database")
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		ID    string      `json:"id"`
+		Ply   int         `json:"ply"`
+		Plies int         `json:"plies"`
+		Board *game.Board `json:"board"`
+	}{rec.ID, ply, len(rec.Moves), board})
+}
+
+// Notation exports a recorded game in notation format
+func (h *Handler) Notation(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.game.Record(mux.Vars(r)["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+		return
 	}
 
-	if err := db.Set(context.Background(), h.game); err != nil {
-		log.WithError(err).Error("unable to set db state")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := game.WriteNotation(w, rec); err != nil {
+		log.WithError(err).Error("error writing notation")
+	}
+}
+
+// Import parses a game in notation format and stores it as a recorded
+// game so it can be replayed
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	rec, err := game.ParseNotation(r.Body)
+	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
+		return
+	}
+
+	rec.ID = h.game.Import(*rec)
+	positions, err := rec.Positions()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
 	}
 
-	updateCh := make(chan game.Game)
-	h.game.UpdatedCh = updateCh
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		*game.Record
+		Positions []game.Board `json:"positions"`
+	}{rec, positions})
+}
 
-	go func() {
-		// this could be handled much better. Have a context for ending gracefully
-		for status := range updateCh {
-			if err := db.Set(context.Background(), status); err != nil {
-				log.WithError(err).Error("error updating store")
-			}
+// displayName is the player's name, or their ID if they have no name
+func displayName(p *game.Player) string {
+	if p == nil {
+		return ""
+	}
+	if p.Name != nil && *p.Name != "" {
+		return *p.Name
+	}
+	return p.ID
+}
+
+// writeBoard renders board as an svg or png image
+func writeBoard(w http.ResponseWriter, format string, board *game.Board, opts render.Options) {
+	var err error
+	switch format {
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		err = render.SVG(w, board, opts)
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		err = render.PNG(w, board, opts)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("error rendering board")
+	}
+}
+
+// BoardImage renders the current board
+func (h *Handler) BoardImage(w http.ResponseWriter, r *http.Request) {
+	state := h.game.State()
+	writeBoard(w, mux.Vars(r)["format"], state.Board, render.Options{
+		XName: displayName(state.X),
+		OName: displayName(state.O),
+	})
+}
+
+// RecordImage renders a recorded game, at the final position unless a
+// ply is given as a query parameter
+func (h *Handler) RecordImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rec, err := h.game.Record(vars["id"])
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	ply := len(rec.Moves)
+	if p := r.URL.Query().Get("ply"); p != "" {
+		if ply, err = strconv.Atoi(p); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
 		}
-	}()
-	log.Info("initialized")
-	w.WriteHeader(http.StatusOK)
+	}
+
+	board, err := rec.Position(ply)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	writeBoard(w, vars["format"], board, render.Options{
+		XName: displayName(&rec.X),
+		OName: displayName(&rec.O),
+	})
 }
 
-func database(ctx context.Context, cfg firebase.Config, fname string) (*db.Ref, error) {
-	opt := option.WithCredentialsFile(fname)
-	app, err := firebase.NewApp(ctx, &cfg, opt)
+// ReplayGIF renders a recorded game as an animated GIF
+func (h *Handler) ReplayGIF(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.game.Record(mux.Vars(r)["id"])
 	if err != nil {
-		return nil, err
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(err.Error()))
+		return
 	}
 
-	db, err := app.Database(ctx)
+	positions, err := rec.Positions()
 	if err != nil {
-		return nil, err
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
 	}
 
-	root := db.NewRef("/")
-	return root, nil
+	w.Header().Set("Content-Type", "image/gif")
+	if err := render.GIF(w, positions, render.Options{
+		XName: displayName(&rec.X),
+		OName: displayName(&rec.O),
+	}, time.Second); err != nil {
+		log.WithError(err).Error("error rendering replay")
+	}
 }
 
-func Route(g *game.Game, store *db.Ref) (*mux.Router, error) {
+// Init sets up the store with the service account key in the body. It
+// is an admin action, and does nothing once the store is set up.
+func (h *Handler) Init(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sc := StoreConfig{ProjectID: vars["projectID"], Bucket: vars["bucket"]}
+	logCtx := log.WithFields(log.Fields{
+		"projectID": sc.ProjectID,
+		"bucket":    sc.Bucket,
+	})
+	logCtx.Info("initializing...")
+
+	bs, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxCredentialsSize))
+	if err != nil {
+		logCtx.WithError(err).Error("error reading body")
+		h.audit(r, "init", sc.ProjectID, sc.Bucket, err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	sc.Credentials = bs
+
+	code, err := h.initStore(r.Context(), sc)
+	h.audit(r, "init", sc.ProjectID, sc.Bucket, err)
+	if err != nil {
+		logCtx.WithError(err).Error("unable to initialize store")
+		w.WriteHeader(code)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	logCtx.Info("initialized")
+	w.WriteHeader(http.StatusOK)
+}
+
+// deprecated marks a legacy route, pointing clients to its successor
+// under /v1 and logging every use so we know when it can be removed.
+// Variables like {id} in successor are filled in from the request, and
+// an {id} not in the path from the id in its JSON body.
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		link := successor
+		for k, v := range mux.Vars(r) {
+			link = strings.Replace(link, "{"+k+"}", v, -1)
+		}
+		if strings.Contains(link, "{id}") {
+			if id := bodyID(r); id != "" {
+				link = strings.Replace(link, "{id}", url.PathEscape(id), -1)
+			} else {
+				// without an id the collection is the closest successor
+				link = strings.Replace(link, "/{id}", "", -1)
+			}
+		}
+
+		log.WithFields(log.Fields{
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"successor":  link,
+			"user_agent": r.UserAgent(),
+		}).Warn("deprecated route used")
+
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		next(w, r)
+	}
+}
+
+// bodyID returns the id in the JSON body of r, for legacy routes that
+// take it there rather than in the path. The body is left to be read
+// again.
+func bodyID(r *http.Request) string {
+	bs, err := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(bs))
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(bs, &body)
+	return body.ID
+}
+
+// NewHandler returns the handler for the HTTP API backed by g
+func NewHandler(g *game.Game, store *db.Ref, cfg Config) (*Handler, error) {
 	if g == nil {
 		return nil, errors.New("need game")
 	}
-	h := &Handler{g, store}
+	schema, err := NewGraphQLSchema(g)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
+	h := &Handler{
+		game:     g,
+		store:    store,
+		schema:   schema,
+		cfg:      cfg,
+		metrics:  newMetrics(g),
+		webhooks: newWebhooks(cfg.Room, cfg.WebhookBackoff),
+		bots:     newBots(g, cfg.EngineCommands),
+	}
+	g.SetMetrics(h.metrics)
+	g.OnEvent(func(e game.Event) {
+		h.webhooks.notify(e)
+		h.bots.notify(e)
+	})
+	h.webhooks.run(cfg.Context)
+	h.bots.run(cfg.Context)
+	return h, nil
+}
+
+// Route returns the router for the HTTP API backed by g
+func Route(g *game.Game, store *db.Ref, cfg Config) (*mux.Router, error) {
+	h, err := NewHandler(g, store, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return h.Router(), nil
+}
+
+// Router routes every endpoint of the HTTP API to h
+func (h *Handler) Router() *mux.Router {
 	r := mux.NewRouter()
 
-	r.HandleFunc("/", h.GetGame).Methods(http.MethodGet)
-	r.HandleFunc("/restart", h.Restart).Methods(http.MethodGet)
-	r.HandleFunc("/init/project/{projectID}/bucket/{bucket}", h.Init).Methods(http.MethodPost)
-	r.HandleFunc("/board/clear", h.Clear).Methods(http.MethodGet)
-
-	p := r.PathPrefix("/player").Subrouter()
-	p.HandleFunc("/move", h.Move).Methods(http.MethodPost)
-	p.HandleFunc("/update", h.UpdatePlayer).Methods(http.MethodPut)
-	p.HandleFunc("/subscribe", h.Subscribe).Methods(http.MethodPost)
-	p.HandleFunc("/unsubscribe", h.Unsubscribe).Methods(http.MethodPost)
-	return r, nil
+	r.HandleFunc("/ui", h.UI).Methods(http.MethodGet)
+	r.Handle("/metrics", h.metrics.handler()).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", h.Healthz).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", h.Readyz).Methods(http.MethodGet)
+
+	v1 := r.PathPrefix("/v1").Subrouter()
+	v1.HandleFunc("/openapi.json", h.OpenAPI).Methods(http.MethodGet)
+	v1.HandleFunc("/graphql", h.GraphQL).Methods(http.MethodGet, http.MethodPost)
+	v1.HandleFunc("/analysis", h.Analyze).Methods(http.MethodPost)
+	v1.Handle("/init/project/{projectID}/bucket/{bucket}", h.requireAdmin(http.HandlerFunc(h.Init))).Methods(http.MethodPost)
+
+	gm := v1.PathPrefix("/game").Subrouter()
+	gm.HandleFunc("", h.GetGame).Methods(http.MethodGet)
+	gm.HandleFunc("", h.Clear).Methods(http.MethodDelete)
+	gm.HandleFunc("/events", h.Events).Methods(http.MethodGet)
+	gm.HandleFunc("/board.{format:svg|png}", h.BoardImage).Methods(http.MethodGet)
+	gm.HandleFunc("/restart", h.Restart).Methods(http.MethodPost)
+	gm.HandleFunc("/moves", h.Move).Methods(http.MethodPost)
+
+	pl := v1.PathPrefix("/players").Subrouter()
+	pl.HandleFunc("", h.Subscribe).Methods(http.MethodPost)
+	pl.HandleFunc("/{id}", h.UpdatePlayer).Methods(http.MethodPut)
+	pl.HandleFunc("/{id}", h.Unsubscribe).Methods(http.MethodDelete)
+
+	gr := v1.PathPrefix("/games").Subrouter()
+	gr.HandleFunc("", h.Games).Methods(http.MethodGet)
+	gr.HandleFunc("", h.Import).Methods(http.MethodPost)
+	gr.HandleFunc("/{id}/notation", h.Notation).Methods(http.MethodGet)
+	gr.HandleFunc("/{id}/board.{format:svg|png}", h.RecordImage).Methods(http.MethodGet)
+	gr.HandleFunc("/{id}/replay", h.Replay).Methods(http.MethodGet)
+	gr.HandleFunc("/{id}/replay.gif", h.ReplayGIF).Methods(http.MethodGet)
+	gr.HandleFunc("/{id}/replay/{ply:[0-9]+}", h.ReplayPly).Methods(http.MethodGet)
+
+	// admin handlers are wrapped one by one: mux skips a subrouter's Use
+	// middleware when a sibling prefix like /v1/game matched first
+	ad := v1.PathPrefix("/admin").Subrouter()
+	admin := func(path, method string, handler http.HandlerFunc) {
+		ad.Handle(path, h.requireAdmin(handler)).Methods(method)
+	}
+	admin("/players/{id}/kick", http.MethodPost, h.AdminKick)
+	admin("/players/{id}/ban", http.MethodPost, h.AdminBan)
+	admin("/queue/{id}", http.MethodPut, h.AdminMoveInQueue)
+	admin("/game/result", http.MethodPost, h.AdminForceResult)
+	admin("/game/pause", http.MethodPost, h.AdminPause)
+	admin("/game/resume", http.MethodPost, h.AdminResume)
+	admin("/broadcast", http.MethodPost, h.AdminBroadcast)
+	admin("/audit", http.MethodGet, h.AdminAudit)
+	admin("/bans", http.MethodGet, h.AdminBans)
+	admin("/bans/{id}", http.MethodDelete, h.AdminUnban)
+	admin("/name-rules", http.MethodGet, h.AdminNameRules)
+	admin("/name-rules", http.MethodPut, h.AdminSetNameRules)
+	admin("/webhooks", http.MethodGet, h.AdminWebhooks)
+	admin("/webhooks", http.MethodPost, h.AdminAddWebhook)
+	admin("/webhooks/dead-letters", http.MethodGet, h.AdminDeadLetters)
+	admin("/webhooks/{id}", http.MethodDelete, h.AdminRemoveWebhook)
+	admin("/webhooks/{id}/test", http.MethodPost, h.AdminTestWebhook)
+	admin("/bots", http.MethodGet, h.AdminBots)
+	admin("/bots", http.MethodPost, h.AdminAddBot)
+	admin("/bots/{id}", http.MethodDelete, h.AdminRemoveBot)
+
+	// legacy routes, kept as aliases until clients have moved to /v1
+	legacy := func(path, method, successor string, handler http.HandlerFunc) {
+		r.HandleFunc(path, deprecated(successor, handler)).Methods(method)
+	}
+	legacy("/", http.MethodGet, "/v1/game", h.GetGame)
+	legacy("/events", http.MethodGet, "/v1/game/events", h.Events)
+	legacy("/openapi.json", http.MethodGet, "/v1/openapi.json", h.OpenAPI)
+	legacy("/graphql", http.MethodGet, "/v1/graphql", h.GraphQL)
+	legacy("/graphql", http.MethodPost, "/v1/graphql", h.GraphQL)
+	legacy("/board.{format:svg|png}", http.MethodGet, "/v1/game/board.{format}", h.BoardImage)
+	legacy("/restart", http.MethodGet, "/v1/game/restart", h.Restart)
+	legacy("/init/project/{projectID}/bucket/{bucket}", http.MethodPost, "/v1/init/project/{projectID}/bucket/{bucket}", h.requireAdmin(http.HandlerFunc(h.Init)).ServeHTTP)
+	legacy("/board/clear", http.MethodGet, "/v1/game", h.Clear)
+	legacy("/games", http.MethodGet, "/v1/games", h.Games)
+	legacy("/games/import", http.MethodPost, "/v1/games", h.Import)
+	legacy("/games/{id}/notation", http.MethodGet, "/v1/games/{id}/notation", h.Notation)
+	legacy("/games/{id}/board.{format:svg|png}", http.MethodGet, "/v1/games/{id}/board.{format}", h.RecordImage)
+	legacy("/games/{id}/replay", http.MethodGet, "/v1/games/{id}/replay", h.Replay)
+	legacy("/games/{id}/replay.gif", http.MethodGet, "/v1/games/{id}/replay.gif", h.ReplayGIF)
+	legacy("/games/{id}/replay/{ply:[0-9]+}", http.MethodGet, "/v1/games/{id}/replay/{ply}", h.ReplayPly)
+	legacy("/player/move", http.MethodPost, "/v1/game/moves", h.Move)
+	legacy("/player/update", http.MethodPut, "/v1/players/{id}", h.UpdatePlayer)
+	legacy("/player/subscribe", http.MethodPost, "/v1/players", h.Subscribe)
+	legacy("/player/unsubscribe", http.MethodPost, "/v1/players/{id}", h.Unsubscribe)
+
+	// count every route by its template, wrapping handlers for the same
+	// reason as the admin routes
+	r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if handler := route.GetHandler(); handler != nil {
+			tpl, _ := route.GetPathTemplate()
+			route.Handler(h.metrics.instrument(tpl, handler))
+		}
+		return nil
+	})
+	return r
 }
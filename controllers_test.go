@@ -0,0 +1,943 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"firebase.google.com/go/db"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/api"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/client"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// varPattern strips the regular expression from a mux path variable
+var varPattern = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(api.OpenAPI, &spec))
+
+	var documented []string
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	r, err := Route(game.New(logrus.WithField("test", true), time.Hour, nil), nil, Config{})
+	assert.NoError(t, err)
+
+	var routed []string
+	err = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// subrouters have no methods
+			return nil
+		}
+		for _, m := range methods {
+			routed = append(routed, m+" "+varPattern.ReplaceAllString(path, "{$1}"))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	sort.Strings(documented)
+	sort.Strings(routed)
+	assert.Equal(t, documented, routed, "expected every route to be in api/openapi.json and nothing else")
+}
+
+func TestClient(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+
+	x, err := c.Subscribe(ctx, game.Player{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, x)
+	o, err := c.Subscribe(ctx, game.Player{ID: "testIDO"})
+	assert.NoError(t, err)
+	assert.Equal(t, "testIDO", o)
+
+	name := "nat"
+	assert.NoError(t, c.UpdatePlayer(ctx, game.Player{ID: x, Name: &name}))
+
+	state, err := c.Game(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, game.InProgress, state.Status)
+	assert.Equal(t, &name, state.X.Name)
+
+	err = c.Move(ctx, game.Move{PlayerID: o})
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusBadRequest, err.(*client.Error).StatusCode)
+	}
+
+	replay, err := c.Import(ctx, "1. a1 a2 2. b1 b2 3. c1")
+	assert.NoError(t, err)
+	assert.Equal(t, game.XWins, replay.Result)
+	assert.Len(t, replay.Positions, 6)
+
+	pos, err := c.ReplayPly(ctx, replay.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, game.Board{{-1, 0, 0}, {0, 0, 0}, {0, 0, 0}}, *pos.Board)
+
+	notation, err := c.Notation(ctx, replay.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, notation, "1. a1 a2 2. b1 b2 3. c1 1-0")
+
+	_, err = c.Replay(ctx, "missing")
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*client.Error).StatusCode)
+	}
+
+	assert.NoError(t, c.Unsubscribe(ctx, o))
+}
+
+func TestAnalysis(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+	gomoku := game.Variant{Width: 15, Height: 15, K: 5}
+	connect4 := game.NewPosition(game.Variant{Width: 7, Height: 6, K: 4})
+	connect4.Board[5][3], connect4.Side = -1, ""
+
+	tCases := []struct {
+		name    string
+		req     client.AnalysisRequest
+		variant game.Variant
+		side    string
+		moves   int
+		best    *game.Move
+		code    int
+	}{
+		{name: "empty standard board by default", variant: game.StandardVariant, side: "X", moves: 9},
+		{
+			name:    "standard board takes the win",
+			req:     client.AnalysisRequest{Position: game.Position{Board: [][]game.Piece{{-1, -1, 0}, {1, 1, 0}, {0, 0, 0}}}},
+			variant: game.StandardVariant,
+			side:    "X",
+			moves:   5,
+			best:    &game.Move{XAxis: 2, YAxis: 0},
+		},
+		{
+			name:    "side from the pieces",
+			req:     client.AnalysisRequest{Position: connect4, Iterations: 500},
+			variant: connect4.Variant,
+			side:    "O",
+			// within two squares of the piece
+			moves: 14,
+		},
+		{
+			name:    "bigger board",
+			req:     client.AnalysisRequest{Position: game.Position{Variant: gomoku}, Iterations: 100},
+			variant: gomoku,
+			side:    "X",
+			moves:   225,
+		},
+		{
+			name: "board not fitting the variant",
+			req:  client.AnalysisRequest{Position: game.Position{Variant: gomoku, Board: [][]game.Piece{{0}}}},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "variant too big",
+			req:  client.AnalysisRequest{Position: game.Position{Variant: game.Variant{Width: 100, Height: 100, K: 5}}},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "finished game",
+			req:  client.AnalysisRequest{Position: game.Position{Board: [][]game.Piece{{-1, -1, -1}, {1, 1, 0}, {0, 0, 0}}, Side: "O"}},
+			code: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := c.Analyze(ctx, tc.req)
+			if tc.code != 0 {
+				if assert.IsType(t, &client.Error{}, err) {
+					assert.Equal(t, tc.code, err.(*client.Error).StatusCode)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.variant, a.Variant)
+			assert.Equal(t, tc.side, a.Side)
+			assert.Len(t, a.Moves, tc.moves)
+			if tc.best != nil {
+				assert.Equal(t, *tc.best, a.Moves[0].Move)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/analysis", strings.NewReader(`{"iterations": -1}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r, err := Route(game.New(logrus.WithField("test", true), time.Hour, nil), nil, Config{})
+	assert.NoError(t, err)
+
+	tCases := []struct {
+		method, path, successor string
+		deprecated              bool
+	}{
+		{http.MethodGet, "/", "</v1/game>", true},
+		{http.MethodGet, "/games/7/replay/2", "</v1/games/7/replay/2>", true},
+		{http.MethodGet, "/v1/game", "", false},
+		{http.MethodGet, "/ui", "", false},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			if tc.deprecated {
+				assert.Equal(t, "true", w.Header().Get("Deprecation"))
+				assert.Equal(t, tc.successor+`; rel="successor-version"`, w.Header().Get("Link"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestLegacyPlayerRoutesLink(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDX"}))
+
+	tCases := []struct {
+		name, method, path, body, successor string
+		code                                int
+	}{
+		{
+			name:      "update",
+			method:    http.MethodPut,
+			path:      "/player/update",
+			body:      `{"id": "testIDX", "name": "foo"}`,
+			successor: "</v1/players/testIDX>",
+			code:      http.StatusOK,
+		},
+		{
+			name:      "update without an id",
+			method:    http.MethodPut,
+			path:      "/player/update",
+			body:      `{"name": "foo"}`,
+			successor: "</v1/players>",
+			code:      http.StatusBadRequest,
+		},
+		{
+			name:      "id with a slash",
+			method:    http.MethodPut,
+			path:      "/player/update",
+			body:      `{"id": "a/b"}`,
+			successor: "</v1/players/a%2Fb>",
+			code:      http.StatusBadRequest,
+		},
+		{
+			name:      "unsubscribe",
+			method:    http.MethodPost,
+			path:      "/player/unsubscribe",
+			body:      `{"id": "testIDX"}`,
+			successor: "</v1/players/testIDX>",
+			code:      http.StatusOK,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			assert.Equal(t, tc.successor+`; rel="successor-version"`, w.Header().Get("Link"))
+			assert.Equal(t, tc.code, w.Code, "expected the handler to still read the body")
+		})
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{AdminToken: "secret"})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+	for _, id := range []string{"x", "o", "a", "b"} {
+		_, err := c.Subscribe(ctx, game.Player{ID: id})
+		assert.NoError(t, err)
+	}
+
+	err = c.Ban(ctx, "a")
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusUnauthorized, err.(*client.Error).StatusCode)
+	}
+
+	c.AdminToken = "secret"
+	assert.NoError(t, c.MoveInQueue(ctx, "b", 0))
+	assert.NoError(t, c.Ban(ctx, "a"))
+	_, err = c.Subscribe(ctx, game.Player{ID: "a"})
+	assert.Error(t, err)
+
+	err = c.Kick(ctx, "missing")
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*client.Error).StatusCode)
+	}
+
+	assert.NoError(t, c.PauseTimeout(ctx))
+	assert.NoError(t, c.Broadcast(ctx, "hello"))
+	state, err := c.Game(ctx)
+	assert.NoError(t, err)
+	assert.True(t, state.Paused)
+	assert.Equal(t, "hello", state.Announcement.Message)
+	assert.Equal(t, []game.Player{{ID: "b"}}, state.Queue)
+
+	assert.NoError(t, c.ForceResult(ctx, game.XWins))
+	err = c.ForceResult(ctx, game.XWins)
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusConflict, err.(*client.Error).StatusCode)
+	}
+
+	entries, err := c.Audit(ctx)
+	assert.NoError(t, err)
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{"unauthorized", "move_in_queue", "ban", "kick", "pause_timeout", "broadcast", "force_result", "force_result"}, actions)
+	assert.NotEmpty(t, entries[len(entries)-1].Error)
+}
+
+func TestAdminDisabled(t *testing.T) {
+	r, err := Route(game.New(logrus.WithField("test", true), time.Hour, nil), nil, Config{})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/audit", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestInit(t *testing.T) {
+	serviceAccount := `{"type": "service_account", "client_email": "a@b.c", "private_key": "key"}`
+	tCases := []struct {
+		name         string
+		path         string
+		token        string
+		body         string
+		store        *db.Ref
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "needs the admin token",
+			body:         serviceAccount,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "unauthorized",
+		},
+		{
+			name:         "needs the admin token on the legacy route",
+			path:         "/init/project/p/bucket/b",
+			body:         serviceAccount,
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: "unauthorized",
+		},
+		{
+			name:         "rejects a body that is not JSON",
+			token:        "secret",
+			body:         "nope",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "credentials must be a service account key in JSON",
+		},
+		{
+			name:         "rejects other kinds of credentials",
+			token:        "secret",
+			body:         `{"type": "external_account", "credential_source": {"file": "/etc/passwd"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "credentials must be a service account key in JSON",
+		},
+		{
+			name:         "rejects a body that is too big",
+			token:        "secret",
+			body:         strings.Repeat(" ", maxCredentialsSize+1),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "http: request body too large",
+		},
+		{
+			name:         "only sets up the store once",
+			token:        "secret",
+			body:         serviceAccount,
+			store:        &db.Ref{},
+			expectedCode: http.StatusConflict,
+			expectedBody: "store already initialized",
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := Route(game.New(logrus.WithField("test", true), time.Hour, nil), tc.store, Config{AdminToken: "secret"})
+			assert.NoError(t, err)
+
+			path := tc.path
+			if path == "" {
+				path = "/v1/init/project/p/bucket/b"
+			}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+
+			_, err = ioutil.ReadFile("credentials.json")
+			assert.Error(t, err, "expected no credentials on disk")
+		})
+	}
+}
+
+func TestModeration(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{AdminToken: "secret"})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+	c.AdminToken = "secret"
+
+	rules, err := c.NameRules(ctx)
+	assert.NoError(t, err)
+	rules.BlockedWords = []string{"rude"}
+	assert.NoError(t, c.SetNameRules(ctx, *rules))
+
+	name := "so rude"
+	_, err = c.Subscribe(ctx, game.Player{Name: &name})
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusUnprocessableEntity, err.(*client.Error).StatusCode)
+		assert.Equal(t, "name_blocked", err.(*client.Error).Code)
+	}
+
+	assert.NoError(t, c.Ban(ctx, "x"))
+	_, err = c.Subscribe(ctx, game.Player{ID: "x"})
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusForbidden, err.(*client.Error).StatusCode)
+		assert.Equal(t, "player_banned", err.(*client.Error).Code)
+	}
+
+	bans, err := c.Bans(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, bans)
+	assert.NoError(t, c.Unban(ctx, "x"))
+	_, err = c.Subscribe(ctx, game.Player{ID: "x"})
+	assert.NoError(t, err)
+}
+
+func TestShutdown(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := NewHandler(g, nil, Config{Context: ctx})
+	assert.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := &http.Server{Handler: h.Router()}
+	go srv.Serve(lis)
+
+	res, err := http.Get("http://" + lis.Addr().String() + "/v1/game/events")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	sctx, scancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer scancel()
+	done := make(chan error)
+	go func() { done <- shutdown(sctx, srv, NewGRPCServer(g), h, g, cancel) }()
+
+	bs, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(bs), "event: goodbye\ndata: server shutting down\n\n"), "expected a goodbye event, got %q", bs)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-sctx.Done():
+		t.Fatal("shutdown did not finish")
+	}
+	assert.Error(t, ctx.Err(), "expected background work to be cancelled")
+
+	_, err = http.Get("http://" + lis.Addr().String() + "/v1/game")
+	assert.Error(t, err, "expected new requests to be refused")
+}
+
+func TestMetrics(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+	for _, id := range []string{"x", "o", "q"} {
+		_, err := c.Subscribe(ctx, game.Player{ID: id})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, c.Move(ctx, game.Move{PlayerID: "x", XAxis: 1, YAxis: 1}))
+	_, err = c.Replay(ctx, "missing")
+	assert.Error(t, err)
+
+	res, err := http.Get(srv.URL + "/metrics")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	bs, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	for _, line := range []string{
+		`tictactoe_games_started_total 1`,
+		`tictactoe_moves_total{auto="false"} 1`,
+		`tictactoe_move_duration_seconds_count{auto="false"} 1`,
+		`tictactoe_queue_length 1`,
+		`tictactoe_active_players 3`,
+		`tictactoe_http_requests_total{code="200",method="POST",route="/v1/players"} 3`,
+		`tictactoe_http_requests_total{code="404",method="GET",route="/v1/games/{id}/replay"} 1`,
+		`tictactoe_store_write_errors_total 0`,
+	} {
+		assert.Contains(t, string(bs), line+"\n")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	tCases := []struct {
+		name         string
+		cfg          Config
+		setup        func(g *game.Game)
+		expectedCode int
+		expected     Readiness
+	}{
+		{
+			name:         "ready without a store",
+			setup:        func(g *game.Game) {},
+			expectedCode: http.StatusOK,
+			expected:     Readiness{Ready: true, Checks: map[string]string{"game": "ok", "store": "ok"}},
+		},
+		{
+			name:         "not ready until Init when a store is required",
+			cfg:          Config{RequireStore: true},
+			setup:        func(g *game.Game) {},
+			expectedCode: http.StatusServiceUnavailable,
+			expected:     Readiness{Checks: map[string]string{"game": "ok", "store": "store not initialized"}},
+		},
+		{
+			name:         "not ready once the game is closed",
+			setup:        func(g *game.Game) { g.Close() },
+			expectedCode: http.StatusServiceUnavailable,
+			expected:     Readiness{Checks: map[string]string{"game": "game closed", "store": "ok"}},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := game.New(logrus.WithField("test", true), time.Hour, nil)
+			r, err := Route(g, nil, tc.cfg)
+			assert.NoError(t, err)
+			tc.setup(g)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "ok\n", rec.Body.String())
+
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			var rd Readiness
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&rd))
+			assert.Equal(t, tc.expected, rd)
+		})
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	type received struct {
+		delivery *client.WebhookDelivery
+		err      error
+	}
+	var (
+		mu       sync.Mutex
+		secret   string
+		requests int
+	)
+	got := make(chan received, 10)
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		d, err := client.VerifyWebhook(r, secret, time.Minute)
+		got <- received{d, err}
+		if first {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer standIn.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, err := Route(g, nil, Config{AdminToken: "secret", WebhookBackoff: time.Millisecond, Context: ctx})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c := client.New(srv.URL)
+	c.AdminToken = "secret"
+
+	_, err = c.AddWebhook(ctx, "ftp://example.com")
+	assert.Error(t, err)
+	_, err = c.AddWebhook(ctx, standIn.URL, "nope")
+	assert.Error(t, err)
+
+	hook, err := c.AddWebhook(ctx, standIn.URL, game.EventPlayerJoined)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hook.Secret)
+	mu.Lock()
+	secret = hook.Secret
+	mu.Unlock()
+	dead, err := c.AddWebhook(ctx, failing.URL)
+	assert.NoError(t, err)
+	// the server hosts the main room, so this one is never sent anything
+	elsewhere, err := c.AddRoomWebhook(ctx, failing.URL, "other")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", elsewhere.Room)
+
+	hooks, err := c.Webhooks(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 3) {
+		assert.Empty(t, hooks[0].Secret, "expected secrets to only be returned once")
+		assert.Equal(t, "other", hooks[2].Room)
+	}
+
+	_, err = c.Subscribe(ctx, game.Player{ID: "x"})
+	assert.NoError(t, err)
+
+	// the first attempt fails and is retried with the same delivery ID
+	var ids []string
+	for i := 0; i < 2; i++ {
+		select {
+		case rec := <-got:
+			if assert.NoError(t, rec.err) {
+				assert.Equal(t, game.EventPlayerJoined, rec.delivery.Type)
+				assert.Equal(t, "x", rec.delivery.Player.ID)
+				ids = append(ids, rec.delivery.ID)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the delivery to be retried")
+		}
+	}
+	if assert.Len(t, ids, 2) {
+		assert.Equal(t, ids[0], ids[1])
+	}
+
+	res, err := c.TestWebhook(ctx, hook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	rec := <-got
+	if assert.NoError(t, rec.err) {
+		assert.Equal(t, game.EventType("ping"), rec.delivery.Type)
+		assert.Equal(t, "main", rec.delivery.Room)
+	}
+
+	_, err = c.TestWebhook(ctx, dead.ID)
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusBadGateway, err.(*client.Error).StatusCode)
+	}
+
+	var letters []client.DeadLetter
+	for start := time.Now(); len(letters) == 0 && time.Since(start) < 5*time.Second; {
+		<-time.After(10 * time.Millisecond)
+		letters, err = c.DeadLetters(ctx)
+		assert.NoError(t, err)
+	}
+	if assert.Len(t, letters, 1) {
+		assert.Equal(t, dead.ID, letters[0].WebhookID)
+		assert.Equal(t, webhookAttempts, letters[0].Attempts)
+		assert.Equal(t, game.EventPlayerJoined, letters[0].Delivery.Type)
+	}
+
+	assert.NoError(t, c.RemoveWebhook(ctx, dead.ID))
+	err = c.RemoveWebhook(ctx, dead.ID)
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*client.Error).StatusCode)
+	}
+}
+
+func TestWebhookRooms(t *testing.T) {
+	tCases := []struct {
+		name   string
+		room   string
+		queued bool
+	}{
+		{name: "every room", room: "", queued: true},
+		{name: "this room", room: "lobby", queued: true},
+		{name: "another room", room: "other", queued: false},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wh := newWebhooks("lobby", time.Millisecond)
+			hook, err := wh.add("http://example.com/hook", tc.room, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.room, hook.Room)
+
+			wh.notify(game.Event{Type: game.EventPlayerJoined})
+			if !tc.queued {
+				assert.Empty(t, wh.queue)
+				return
+			}
+			select {
+			case job := <-wh.queue:
+				assert.Equal(t, hook.ID, job.hook.ID)
+				assert.Equal(t, "lobby", job.delivery.Room)
+			default:
+				t.Error("expected a delivery for the webhook")
+			}
+		})
+	}
+}
+
+// firstOpenBot is a bot that plays the first open square, reading by row
+func firstOpenBot(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var g game.Game
+		if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		played := map[[2]int]bool{}
+		for _, m := range g.History {
+			played[[2]int{m.XAxis, m.YAxis}] = true
+		}
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 3; x++ {
+				if !played[[2]int{x, y}] {
+					json.NewEncoder(w).Encode(game.Move{XAxis: x, YAxis: y, PlayerID: "ignored"})
+					return
+				}
+			}
+		}
+	}))
+}
+
+func TestBots(t *testing.T) {
+	tCases := []struct {
+		name    string
+		timeout time.Duration
+		failO   bool
+		// offBoardO answers with a move off the board
+		offBoardO bool
+		engines   bool
+		result    game.Status
+	}{
+		{name: "bots play each other", timeout: time.Hour, result: game.XWins},
+		{name: "engine bots play each other", timeout: time.Hour, engines: true, result: game.XWins},
+		{name: "a failing bot is moved for on timeout", timeout: 20 * time.Millisecond, failO: true},
+		{name: "a bot moving off the board is moved for on timeout", timeout: 20 * time.Millisecond, offBoardO: true},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			good := firstOpenBot(t)
+			defer good.Close()
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer failing.Close()
+			offBoard := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(game.Move{XAxis: 9})
+			}))
+			defer offBoard.Close()
+
+			g := game.New(logrus.WithField("test", true), tc.timeout, nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r, err := Route(g, nil, Config{AdminToken: "secret", Context: ctx})
+			assert.NoError(t, err)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+			c := client.New(srv.URL)
+			c.AdminToken = "secret"
+
+			if tc.engines {
+				x, err := c.AddEngineBot(ctx, game.Player{ID: "botX"}, "minimax")
+				assert.NoError(t, err)
+				assert.True(t, x.Player.Bot)
+				_, err = c.AddEngineBot(ctx, game.Player{}, "first")
+				assert.NoError(t, err)
+			} else {
+				x, err := c.AddBot(ctx, game.Player{ID: "botX"}, good.URL)
+				assert.NoError(t, err)
+				assert.True(t, x.Player.Bot)
+				oURL := good.URL
+				if tc.failO {
+					oURL = failing.URL
+				}
+				if tc.offBoardO {
+					oURL = offBoard.URL
+				}
+				_, err = c.AddBot(ctx, game.Player{}, oURL)
+				assert.NoError(t, err)
+			}
+
+			var records []game.Record
+			for start := time.Now(); len(records) == 0 && time.Since(start) < 5*time.Second; {
+				<-time.After(10 * time.Millisecond)
+				records = g.Records()
+			}
+			g.Close()
+			if assert.Len(t, records, 1) {
+				assert.Equal(t, "botX", records[0].X.ID)
+				assert.True(t, records[0].X.Bot)
+				assert.True(t, records[0].O.Bot)
+				if tc.result != "" {
+					assert.Equal(t, tc.result, records[0].Result)
+				}
+			}
+
+			bots, err := c.Bots(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, bots, 2)
+		})
+	}
+}
+
+func TestBotRegistration(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{AdminToken: "secret"})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	ctx := context.Background()
+	c := client.New(srv.URL)
+	c.AdminToken = "secret"
+
+	_, err = c.AddBot(ctx, game.Player{}, "not a url")
+	assert.Error(t, err)
+	_, err = c.AddEngineBot(ctx, game.Player{}, "not an engine")
+	assert.Error(t, err)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/admin/bots",
+		strings.NewReader(`{"url": "http://127.0.0.1:1", "engine": "first"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	res, err := http.DefaultClient.Do(req)
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "bots have a url or an engine, not both")
+	}
+	_, err = c.AddBot(ctx, game.Player{ID: "bot"}, "http://127.0.0.1:1")
+	assert.NoError(t, err)
+	_, err = c.AddBot(ctx, game.Player{ID: "bot"}, "http://127.0.0.1:1")
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusBadRequest, err.(*client.Error).StatusCode)
+	}
+
+	// players can't make themselves bots
+	_, err = c.Subscribe(ctx, game.Player{ID: "human", Bot: true})
+	assert.NoError(t, err)
+	name := "renamed"
+	assert.NoError(t, c.UpdatePlayer(ctx, game.Player{ID: "bot", Name: &name}))
+	assert.True(t, g.X.Bot)
+	assert.False(t, g.O.Bot)
+
+	assert.NoError(t, c.RemoveBot(ctx, "bot"))
+	assert.Nil(t, g.X)
+	err = c.RemoveBot(ctx, "bot")
+	if assert.IsType(t, &client.Error{}, err) {
+		assert.Equal(t, http.StatusNotFound, err.(*client.Error).StatusCode)
+	}
+}
+
+func TestBotMoveOffBoard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(game.Move{XAxis: 9})
+	}))
+	defer srv.Close()
+
+	b := newBots(game.New(logrus.WithField("test", true), time.Hour, nil), nil)
+	_, err := b.ask(context.Background(), Bot{URL: srv.URL})
+	assert.Equal(t, errBotMove, err)
+}
+
+func TestBotsPlayTurnsAtOnce(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer slow.Close()
+	defer close(release)
+	asked := make(chan string, 1)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked <- r.Header.Get("X-Bot-Player-ID")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer fast.Close()
+
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := newBots(g, nil)
+	b.run(ctx)
+	slowBot, err := b.add(Bot{Player: game.Player{ID: "slow"}, URL: slow.URL})
+	assert.NoError(t, err)
+	fastBot, err := b.add(Bot{Player: game.Player{ID: "fast"}, URL: fast.URL})
+	assert.NoError(t, err)
+
+	// the slow bot doesn't answer until the test ends
+	deadline := time.Now().Add(time.Hour)
+	b.notify(game.Event{Type: game.EventYourTurn, Player: &slowBot.Player, Deadline: &deadline})
+	b.notify(game.Event{Type: game.EventYourTurn, Player: &fastBot.Player, Deadline: &deadline})
+	select {
+	case id := <-asked:
+		assert.Equal(t, "fast", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the fast bot to be asked while the slow one thinks")
+	}
+}
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Admin errors
+var (
+	ErrInvalidPosition   = errors.New("invalid queue position")
+	ErrInvalidResult     = errors.New("invalid result")
+	ErrNoGameInProgress  = errors.New("no game in progress")
+	ErrEmptyAnnouncement = errors.New("empty announcement")
+)
+
+// Announcement is a message broadcast to everyone following the game
+type Announcement struct {
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+}
+
+// Kick removes a player from the game or the queue. They are free to
+// subscribe again.
+func (g *Game) Kick(id string) error {
+	g.lock()
+	defer g.unlock()
+	g.log.WithField("player_id", id).Warn("kicking player")
+	return g.removePlayer(id)
+}
+
+// MoveInQueue moves a queued player to pos, where 0 is next to play
+func (g *Game) MoveInQueue(id string, pos int) error {
+	g.lock()
+	defer g.unlock()
+	logCtx := g.log.WithFields(logrus.Fields{"player_id": id, "position": pos})
+	if pos < 0 || pos >= len(g.Queue) {
+		logCtx.Error("queue position out of range")
+		return ErrInvalidPosition
+	}
+
+	idx := -1
+	for i := range g.Queue {
+		if g.Queue[i].ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		logCtx.Error("player not in queue")
+		return ErrPlayerNotFound
+	}
+
+	defer g.update()
+	p := g.Queue[idx]
+	g.Queue = append(g.Queue[:idx], g.Queue[idx+1:]...)
+	g.Queue = append(g.Queue[:pos], append([]Player{p}, g.Queue[pos:]...)...)
+	logCtx.Info("player moved in queue")
+	return nil
+}
+
+// ForceResult ends the game in progress with s, which must be XWins,
+// OWins or Cats. The game is recorded and the next one starts as if it
+// had been played out.
+func (g *Game) ForceResult(s Status) error {
+	g.lock()
+	defer g.unlock()
+	logCtx := g.log.WithField("result", s)
+	switch s {
+	case XWins, OWins, Cats:
+	default:
+		logCtx.Error("result is not a finished game")
+		return ErrInvalidResult
+	}
+	if g.Status != InProgress {
+		logCtx.WithField("status", g.Status).Error("no game to end")
+		return ErrNoGameInProgress
+	}
+
+	defer g.update()
+	logCtx.Warn("forcing result")
+	g.Status = s
+	g.gameOver(logCtx)
+	return nil
+}
+
+// PauseTimeout stops moves being made for players who run out of time
+// until ResumeTimeout is called
+func (g *Game) PauseTimeout() {
+	g.lock()
+	defer g.unlock()
+	if g.Paused {
+		return
+	}
+	defer g.update()
+	g.log.Info("pausing timeout")
+	g.Paused = true
+	if g.Status == InProgress {
+		g.stopTimeout()
+	}
+}
+
+// ResumeTimeout restarts the move timeout after PauseTimeout, giving the
+// player to move the full timeout again
+func (g *Game) ResumeTimeout() {
+	g.lock()
+	defer g.unlock()
+	if !g.Paused {
+		return
+	}
+	defer g.update()
+	g.log.Info("resuming timeout")
+	g.Paused = false
+	if g.Status == InProgress {
+		g.startTimeout()
+	}
+}
+
+// Broadcast sets the announcement shown to everyone following the game.
+// An announcement replaces the previous one.
+func (g *Game) Broadcast(message string) error {
+	if message == "" {
+		return ErrEmptyAnnouncement
+	}
+	g.lock()
+	defer g.unlock()
+	defer g.update()
+	g.log.WithField("message", message).Info("broadcasting announcement")
+	g.Announcement = &Announcement{Message: message, Date: time.Now().UTC()}
+	return nil
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveInQueue(t *testing.T) {
+	tCases := []struct {
+		name     string
+		id       string
+		pos      int
+		expected []string
+		err      error
+	}{
+		{name: "moves a player to the front", id: "c", pos: 0, expected: []string{"c", "a", "b"}},
+		{name: "moves a player to the back", id: "a", pos: 2, expected: []string{"b", "c", "a"}},
+		{name: "keeps a player in place", id: "b", pos: 1, expected: []string{"a", "b", "c"}},
+		{name: "rejects a position past the end", id: "a", pos: 3, expected: []string{"a", "b", "c"}, err: ErrInvalidPosition},
+		{name: "rejects a negative position", id: "a", pos: -1, expected: []string{"a", "b", "c"}, err: ErrInvalidPosition},
+		{name: "rejects a player not in the queue", id: "x", pos: 0, expected: []string{"a", "b", "c"}, err: ErrPlayerNotFound},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := New(logrus.WithField("test", true), time.Hour, nil)
+			g.Queue = []Player{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+			assert.Equal(t, tc.err, g.MoveInQueue(tc.id, tc.pos))
+			var ids []string
+			for _, p := range g.Queue {
+				ids = append(ids, p.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestBan(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.NoError(t, g.AddPlayer(Player{ID: "x"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "o"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "q"}))
+
+	assert.NoError(t, g.Ban("q"))
+	assert.Empty(t, g.Queue)
+	assert.Equal(t, ErrPlayerBanned, g.AddPlayer(Player{ID: "q"}))
+
+	// players who are not around can still be banned
+	assert.NoError(t, g.Ban("absent"))
+	assert.Equal(t, ErrPlayerBanned, g.AddPlayer(Player{ID: "absent"}))
+
+	assert.NoError(t, g.Kick("o"))
+	assert.Nil(t, g.O)
+	assert.NoError(t, g.AddPlayer(Player{ID: "o"}), "kicked players may come back")
+}
+
+func TestForceResult(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.Equal(t, ErrNoGameInProgress, g.ForceResult(XWins))
+
+	assert.NoError(t, g.AddPlayer(Player{ID: "x"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "o"}))
+	assert.Equal(t, ErrInvalidResult, g.ForceResult(InProgress))
+
+	assert.NoError(t, g.ForceResult(OWins))
+	assert.Equal(t, OWins, g.Status)
+	if records := g.Records(); assert.Len(t, records, 1) {
+		assert.Equal(t, OWins, records[0].Result)
+	}
+}
+
+func TestPauseTimeout(t *testing.T) {
+	g := New(logrus.WithField("test", true), 20*time.Millisecond, nil)
+	g.PauseTimeout()
+	assert.NoError(t, g.AddPlayer(Player{ID: "x"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "o"}))
+
+	<-time.After(100 * time.Millisecond)
+	assert.Empty(t, g.State().History, "no moves are made while paused")
+
+	g.ResumeTimeout()
+	<-time.After(100 * time.Millisecond)
+	g.PauseTimeout()
+	assert.NotEmpty(t, g.State().History, "moves are made once resumed")
+}
+
+func TestBroadcast(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	updates, stop := g.Watch()
+	defer stop()
+
+	assert.Equal(t, ErrEmptyAnnouncement, g.Broadcast(""))
+	assert.NoError(t, g.Broadcast("back in five"))
+
+	select {
+	case state := <-updates:
+		if assert.NotNil(t, state.Announcement) {
+			assert.Equal(t, "back in five", state.Announcement.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an update")
+	}
+}
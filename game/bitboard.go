@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrBitboardOverlap is returned when a bitboard has a square claimed by
+// both X and O
+var ErrBitboardOverlap = errors.New("bitboard has overlapping pieces")
+
+// Bitboard is a compact representation of a Board. Each player has a
+// 9 bit mask where bit (y*3 + x) is set if the player has a piece on
+// that square.
+type Bitboard struct {
+	X uint16
+	O uint16
+}
+
+const fullBitboard uint16 = 0x1ff
+
+// winMasks are all 8 winning lines on a 3x3 board, precomputed so win
+// detection is a handful of ANDs rather than a rescan of the board
+var winMasks = [...]uint16{
+	// rows
+	0x007, 0x038, 0x1c0,
+	// columns
+	0x049, 0x092, 0x124,
+	// diagonals
+	0x111, 0x054,
+}
+
+func bit(x, y int) uint16 { return 1 << uint(y*3+x) }
+
+// NewBitboard converts b to a Bitboard. A nil board is treated as empty.
+func NewBitboard(b *Board) Bitboard {
+	var bb Bitboard
+	if b == nil {
+		return bb
+	}
+	for y := range b {
+		for x := range b[y] {
+			switch b[y][x] {
+			case xPiece:
+				bb.X |= bit(x, y)
+			case oPiece:
+				bb.O |= bit(x, y)
+			}
+		}
+	}
+	return bb
+}
+
+// Board converts bb back to the Board representation
+func (bb Bitboard) Board() *Board {
+	var b Board
+	for y := range b {
+		for x := range b[y] {
+			switch {
+			case bb.X&bit(x, y) != 0:
+				b[y][x] = xPiece
+			case bb.O&bit(x, y) != 0:
+				b[y][x] = oPiece
+			}
+		}
+	}
+	return &b
+}
+
+// At returns the piece at x, y
+func (bb Bitboard) At(x, y int) Piece {
+	switch {
+	case bb.X&bit(x, y) != 0:
+		return xPiece
+	case bb.O&bit(x, y) != 0:
+		return oPiece
+	}
+	return blank
+}
+
+// Empty returns a mask of all unoccupied squares
+func (bb Bitboard) Empty() uint16 { return ^(bb.X | bb.O) & fullBitboard }
+
+// Place returns a copy of bb with p placed at x, y. The square is not
+// checked; callers are expected to consult Empty first.
+func (bb Bitboard) Place(p Piece, x, y int) Bitboard {
+	switch p {
+	case xPiece:
+		bb.X |= bit(x, y)
+	case oPiece:
+		bb.O |= bit(x, y)
+	}
+	return bb
+}
+
+// Status returns the board result without considering players, one of
+// XWins, OWins, Cats or InProgress
+func (bb Bitboard) Status() Status {
+	for _, m := range winMasks {
+		if bb.X&m == m {
+			return XWins
+		}
+		if bb.O&m == m {
+			return OWins
+		}
+	}
+	if bb.X|bb.O == fullBitboard {
+		return Cats
+	}
+	return InProgress
+}
+
+// WinningLine returns the x, y positions of the first completed line, if
+// there is one
+func (bb Bitboard) WinningLine() ([][2]int, bool) {
+	for _, m := range winMasks {
+		if bb.X&m != m && bb.O&m != m {
+			continue
+		}
+		line := make([][2]int, 0, 3)
+		for i := uint(0); i < 9; i++ {
+			if m&(1<<i) != 0 {
+				line = append(line, [2]int{int(i) % 3, int(i) / 3})
+			}
+		}
+		return line, true
+	}
+	return nil, false
+}
+
+// MarshalJSON encodes bb in the same format as Board so either can be
+// sent to clients
+func (bb Bitboard) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bb.Board())
+}
+
+// UnmarshalJSON decodes a Board formatted JSON array into bb
+func (bb *Bitboard) UnmarshalJSON(data []byte) error {
+	var b Board
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	*bb = NewBitboard(&b)
+	return nil
+}
+
+// Validate returns ErrBitboardOverlap if any square belongs to both players
+func (bb Bitboard) Validate() error {
+	if bb.X&bb.O != 0 {
+		return ErrBitboardOverlap
+	}
+	return nil
+}
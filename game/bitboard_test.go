@@ -0,0 +1,211 @@
+package game
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitboardStatus(t *testing.T) {
+	tCases := []struct {
+		name     string
+		board    *Board
+		expected Status
+	}{
+		{
+			name: "Returns in progress for an empty board",
+			board: tstBoardPtr(
+				0, 0, 0,
+				0, 0, 0,
+				0, 0, 0,
+			),
+			expected: InProgress,
+		},
+		{
+			name: "Returns X wins for horizontal",
+			board: tstBoardPtr(
+				-1, -1, -1,
+				1, 1, 0,
+				0, 0, 0,
+			),
+			expected: XWins,
+		},
+		{
+			name: "Returns O wins for vertical",
+			board: tstBoardPtr(
+				-1, 1, -1,
+				0, 1, -1,
+				0, 1, 0,
+			),
+			expected: OWins,
+		},
+		{
+			name: "Returns X wins for anti diagonal",
+			board: tstBoardPtr(
+				1, 1, -1,
+				0, -1, 0,
+				-1, 0, 0,
+			),
+			expected: XWins,
+		},
+		{
+			name: "Returns cats for a full board with no winner",
+			board: tstBoardPtr(
+				-1, 1, -1,
+				1, -1, 1,
+				1, -1, 1,
+			),
+			expected: Cats,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bb := NewBitboard(tc.board)
+			assert.Equal(t, tc.expected, bb.Status())
+
+			assert.Equal(t, scanStatus(tc.board), bb.Status(),
+				"expected bitboard status to agree with the row and column scan")
+		})
+	}
+}
+
+// scanStatus is the row and column scan status used before Bitboard,
+// kept to check Bitboard against. A line summing to -3 is three Xs and
+// one summing to 3 is three Os.
+func scanStatus(b *Board) Status {
+	score := func(sum Piece) (Status, bool) {
+		switch sum {
+		case 3 * xPiece:
+			return XWins, true
+		case 3 * oPiece:
+			return OWins, true
+		}
+		return "", false
+	}
+
+	movesLeft := 9
+	colSums := [...]Piece{0, 0, 0}
+	for _, row := range b {
+		rowSum := Piece(0)
+		for i, col := range row {
+			if col != blank {
+				movesLeft--
+			}
+			rowSum += col
+			colSums[i] += col
+		}
+		if s, ok := score(rowSum); ok {
+			return s
+		}
+	}
+	for _, col := range colSums {
+		if s, ok := score(col); ok {
+			return s
+		}
+	}
+	if s, ok := score(b[0][0] + b[1][1] + b[2][2]); ok {
+		return s
+	}
+	if s, ok := score(b[2][0] + b[1][1] + b[0][2]); ok {
+		return s
+	}
+
+	if movesLeft == 0 {
+		return Cats
+	}
+	return InProgress
+}
+
+func TestStatusRandomPositions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		// play random moves until the game ends or a random point in it
+		var b Board
+		stop := r.Intn(10)
+		piece := xPiece
+		for n := 0; n < stop && scanStatus(&b) == InProgress; n++ {
+			empty := [][2]int{}
+			for y := range b {
+				for x := range b[y] {
+					if b[y][x] == blank {
+						empty = append(empty, [2]int{x, y})
+					}
+				}
+			}
+			sq := empty[r.Intn(len(empty))]
+			b[sq[1]][sq[0]] = piece
+			piece = -piece
+		}
+
+		g := Game{Board: &b, X: &Player{}, O: &Player{}}
+		if !assert.Equal(t, scanStatus(&b), g.status(), "board %v", b) {
+			return
+		}
+		assert.Equal(t, scanStatus(&b), NewBitboard(&b).Status(), "board %v", b)
+	}
+}
+
+func TestBitboardRoundTrip(t *testing.T) {
+	board := tstBoardPtr(
+		-1, 1, 0,
+		0, -1, 1,
+		0, 0, 0,
+	)
+	bb := NewBitboard(board)
+	assert.NoError(t, bb.Validate())
+	assert.Equal(t, board, bb.Board())
+	assert.Equal(t, xPiece, bb.At(0, 0))
+	assert.Equal(t, oPiece, bb.At(2, 1))
+	assert.Equal(t, blank, bb.At(2, 0))
+
+	boardJSON, err := json.Marshal(board)
+	assert.NoError(t, err)
+	bbJSON, err := json.Marshal(bb)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(boardJSON), string(bbJSON))
+
+	var decoded Bitboard
+	assert.NoError(t, json.Unmarshal(boardJSON, &decoded))
+	assert.Equal(t, bb, decoded)
+}
+
+func TestBitboardWinningLine(t *testing.T) {
+	line, ok := NewBitboard(tstBoardPtr(
+		1, 1, -1,
+		0, -1, 0,
+		-1, 0, 0,
+	)).WinningLine()
+	assert.True(t, ok)
+	assert.Equal(t, [][2]int{{2, 0}, {1, 1}, {0, 2}}, line)
+
+	_, ok = NewBitboard(tstBoardPtr(
+		1, 1, -1,
+		0, 0, 0,
+		-1, 0, 0,
+	)).WinningLine()
+	assert.False(t, ok)
+}
+
+var benchBoard = tstBoardPtr(
+	-1, 1, -1,
+	1, -1, 1,
+	1, -1, 0,
+)
+
+var benchStatus Status
+
+func BenchmarkBoardStatus(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchStatus = scanStatus(benchBoard)
+	}
+}
+
+func BenchmarkBitboardStatus(b *testing.B) {
+	bb := NewBitboard(benchBoard)
+	for i := 0; i < b.N; i++ {
+		benchStatus = bb.Status()
+	}
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// Engine errors
+var (
+	ErrNoMoves     = errors.New("no moves left")
+	ErrInvalidSide = errors.New("side must be X or O")
+)
+
+// Engine chooses moves
+type Engine interface {
+	// BestMove returns the move for side, X or O, to play on bb. It should
+	// return by the time ctx is done. PlayerID is left empty.
+	BestMove(ctx context.Context, bb Bitboard, side string) (Move, error)
+}
+
+// BuiltinEngine returns the engine in this package called name: first,
+// random, minimax or mcts. seed decides the moves of random and mcts.
+func BuiltinEngine(name string, seed int64) (Engine, bool) {
+	switch name {
+	case "first":
+		return FirstOpenEngine{}, true
+	case "random":
+		return NewRandomEngine(seed), true
+	case "minimax":
+		return NewMinimaxEngine(), true
+	case "mcts":
+		return NewMCTSEngine(seed), true
+	}
+	return nil, false
+}
+
+// sidePieces returns the mask of side's pieces and of the other side's
+func sidePieces(bb Bitboard, side string) (mine, theirs uint16, err error) {
+	switch side {
+	case "X":
+		return bb.X, bb.O, nil
+	case "O":
+		return bb.O, bb.X, nil
+	}
+	return 0, 0, ErrInvalidSide
+}
+
+// squareMove is the move on the square with index i, as used by bit
+func squareMove(i int) Move {
+	return Move{XAxis: i % 3, YAxis: i / 3}
+}
+
+// openSquares checks a move can be made for side on bb and returns the
+// empty squares
+func openSquares(bb Bitboard, side string) (uint16, error) {
+	if _, _, err := sidePieces(bb, side); err != nil {
+		return 0, err
+	}
+	if bb.Status() != InProgress {
+		return 0, ErrNoMoves
+	}
+	return bb.Empty(), nil
+}
+
+// Play returns a copy of bb with side's piece placed on the square of m,
+// checking the move can be made
+func (bb Bitboard) Play(side string, m Move) (Bitboard, error) {
+	empty, err := openSquares(bb, side)
+	if err != nil {
+		return bb, err
+	}
+	if m.XAxis < 0 || m.XAxis > 2 || m.YAxis < 0 || m.YAxis > 2 || empty&bit(m.XAxis, m.YAxis) == 0 {
+		return bb, ErrInvalidMove
+	}
+	if side == "X" {
+		return bb.Place(xPiece, m.XAxis, m.YAxis), nil
+	}
+	return bb.Place(oPiece, m.XAxis, m.YAxis), nil
+}
+
+// FirstOpenEngine plays the first open square, reading by row. It is the
+// move made for players who time out.
+type FirstOpenEngine struct{}
+
+// BestMove ...
+func (FirstOpenEngine) BestMove(ctx context.Context, bb Bitboard, side string) (Move, error) {
+	empty, err := openSquares(bb, side)
+	if err != nil {
+		return Move{}, err
+	}
+	return squareMove(bits.TrailingZeros16(empty)), nil
+}
+
+// RandomEngine plays any open square
+type RandomEngine struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandomEngine returns a RandomEngine whose moves are decided by seed
+func NewRandomEngine(seed int64) *RandomEngine {
+	return &RandomEngine{rand: rand.New(rand.NewSource(seed))}
+}
+
+// BestMove ...
+func (e *RandomEngine) BestMove(ctx context.Context, bb Bitboard, side string) (Move, error) {
+	empty, err := openSquares(bb, side)
+	if err != nil {
+		return Move{}, err
+	}
+	e.mu.Lock()
+	n := e.rand.Intn(bits.OnesCount16(empty))
+	e.mu.Unlock()
+	return squareMove(nthSquare(empty, n)), nil
+}
+
+// nthSquare returns the index of the nth set bit of squares
+func nthSquare(squares uint16, n int) int {
+	for ; n > 0; n-- {
+		squares &= squares - 1
+	}
+	return bits.TrailingZeros16(squares)
+}
+
+// MinimaxEngine plays perfectly: it never loses, and wins as soon as it
+// can. Positions are remembered, so after the first few games every move
+// is a lookup.
+type MinimaxEngine struct {
+	mu   sync.Mutex
+	memo map[[2]uint16]int
+}
+
+// NewMinimaxEngine returns a MinimaxEngine that has not seen any positions
+func NewMinimaxEngine() *MinimaxEngine {
+	return &MinimaxEngine{memo: map[[2]uint16]int{}}
+}
+
+// BestMove returns the first of the best moves, reading by row
+func (e *MinimaxEngine) BestMove(ctx context.Context, bb Bitboard, side string) (Move, error) {
+	empty, err := openSquares(bb, side)
+	if err != nil {
+		return Move{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Move{}, err
+	}
+	mine, theirs, _ := sidePieces(bb, side)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	best, bestScore := -1, 0
+	for sq := empty; sq != 0; sq &= sq - 1 {
+		i := bits.TrailingZeros16(sq)
+		score := -e.score(theirs, mine|1<<uint(i))
+		if best == -1 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return squareMove(best), nil
+}
+
+// score is the value of the position to the side with mine, to move:
+// positive when it wins with best play, and higher the sooner it wins.
+// The caller holds mu.
+func (e *MinimaxEngine) score(mine, theirs uint16) int {
+	key := [2]uint16{mine, theirs}
+	if s, ok := e.memo[key]; ok {
+		return s
+	}
+
+	empty := ^(mine | theirs) & fullBitboard
+	var s int
+	switch {
+	case hasLine(theirs):
+		// the other side just won, the more squares left the sooner
+		s = -(1 + bits.OnesCount16(empty))
+	case empty == 0:
+		s = 0
+	default:
+		s = -10
+		for sq := empty; sq != 0; sq &= sq - 1 {
+			if v := -e.score(theirs, mine|sq&-sq); v > s {
+				s = v
+			}
+		}
+	}
+	e.memo[key] = s
+	return s
+}
+
+// hasLine reports whether the pieces in mask make a winning line
+func hasLine(mask uint16) bool {
+	for _, m := range winMasks {
+		if mask&m == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// bitboardOf builds a bitboard from rows of x, o and . from a1 to c3
+func bitboardOf(rows ...string) Bitboard {
+	var bb Bitboard
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'x':
+				bb = bb.Place(xPiece, x, y)
+			case 'o':
+				bb = bb.Place(oPiece, x, y)
+			}
+		}
+	}
+	return bb
+}
+
+func TestMinimaxEngine(t *testing.T) {
+	tCases := []struct {
+		name     string
+		board    Bitboard
+		side     string
+		expected Move
+		err      error
+	}{
+		{
+			name:     "takes the win",
+			board:    bitboardOf("xx.", "oo.", "..."),
+			side:     "X",
+			expected: Move{XAxis: 2, YAxis: 0},
+		},
+		{
+			name:     "takes the win as O",
+			board:    bitboardOf("xx.", "oo.", "x.."),
+			side:     "O",
+			expected: Move{XAxis: 2, YAxis: 1},
+		},
+		{
+			name:     "blocks",
+			board:    bitboardOf("x..", ".x.", "..."),
+			side:     "O",
+			expected: Move{XAxis: 2, YAxis: 2},
+		},
+		{
+			name:     "plays an edge between opposite corners",
+			board:    bitboardOf("x..", ".o.", "..x"),
+			side:     "O",
+			expected: Move{XAxis: 1, YAxis: 0},
+		},
+		{
+			name:  "no moves on a full board",
+			board: bitboardOf("xox", "xoo", "oxx"),
+			side:  "X",
+			err:   ErrNoMoves,
+		},
+		{
+			name:  "no moves once the game is won",
+			board: bitboardOf("xxx", "oo.", "..."),
+			side:  "O",
+			err:   ErrNoMoves,
+		},
+		{
+			name:  "needs a side",
+			board: bitboardOf("...", "...", "..."),
+			side:  "Y",
+			err:   ErrInvalidSide,
+		},
+	}
+
+	e := NewMinimaxEngine()
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			move, err := e.BestMove(context.Background(), tc.board, tc.side)
+			assert.Equal(t, tc.err, err)
+			if err == nil {
+				assert.Equal(t, tc.expected, move)
+			}
+		})
+	}
+}
+
+// playEngines plays a game between x and o and returns the result
+func playEngines(t *testing.T, x, o Engine) Status {
+	var bb Bitboard
+	engines := map[string]Engine{"X": x, "O": o}
+	side := "X"
+	for bb.Status() == InProgress {
+		move, err := engines[side].BestMove(context.Background(), bb, side)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		bb, err = bb.Play(side, move)
+		if !assert.NoError(t, err, "%s played %s", side, move.Square()) {
+			return ""
+		}
+		if side == "X" {
+			side = "O"
+		} else {
+			side = "X"
+		}
+	}
+	return bb.Status()
+}
+
+func TestMinimaxNeverLoses(t *testing.T) {
+	minimax, random := NewMinimaxEngine(), NewRandomEngine(1)
+	for i := 0; i < 200; i++ {
+		assert.NotEqual(t, OWins, playEngines(t, minimax, random))
+		assert.NotEqual(t, XWins, playEngines(t, random, minimax))
+	}
+	assert.Equal(t, Cats, playEngines(t, minimax, minimax))
+}
+
+func TestPlay(t *testing.T) {
+	tCases := []struct {
+		name     string
+		board    Bitboard
+		side     string
+		move     Move
+		expected Bitboard
+		err      error
+	}{
+		{name: "places X", board: bitboardOf("...", "...", "..."), side: "X", move: Move{XAxis: 1, YAxis: 2}, expected: bitboardOf("...", "...", ".x.")},
+		{name: "places O", board: bitboardOf("x..", "...", "..."), side: "O", move: Move{XAxis: 2, YAxis: 0}, expected: bitboardOf("x.o", "...", "...")},
+		{name: "rejects a taken square", board: bitboardOf("x..", "...", "..."), side: "O", move: Move{}, expected: bitboardOf("x..", "...", "..."), err: ErrInvalidMove},
+		{name: "rejects a square off the board", board: bitboardOf("...", "...", "..."), side: "X", move: Move{XAxis: 3}, expected: bitboardOf("...", "...", "..."), err: ErrInvalidMove},
+		{name: "rejects a finished game", board: bitboardOf("xxx", "oo.", "..."), side: "O", move: Move{XAxis: 2, YAxis: 1}, expected: bitboardOf("xxx", "oo.", "..."), err: ErrNoMoves},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bb, err := tc.board.Play(tc.side, tc.move)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, bb)
+		})
+	}
+}
+
+func TestRandomEngine(t *testing.T) {
+	bb := bitboardOf("xo.", "ox.", "...")
+	seen := map[Move]bool{}
+	e := NewRandomEngine(1)
+	for i := 0; i < 100; i++ {
+		move, err := e.BestMove(context.Background(), bb, "O")
+		assert.NoError(t, err)
+		assert.NotZero(t, bb.Empty()&bit(move.XAxis, move.YAxis))
+		seen[move] = true
+	}
+	assert.Len(t, seen, 5, "expected every open square to be played")
+}
+
+// TestHelperEngine is not a test: it is the engine run by the process
+// engine tests, answering with the first open square. In late mode it
+// answers its first go only when told to stop, and in mute mode it never
+// answers.
+func TestHelperEngine(t *testing.T) {
+	mode := os.Getenv("TTT_HELPER_ENGINE")
+	if mode == "" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	var board string
+	bestmove := func() {
+		fmt.Println("bestmove", squareMove(strings.IndexByte(board, '.')).Square())
+	}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case mode == "mute" || len(fields) == 0:
+		case fields[0] == "ttt":
+			fmt.Println("id name first open")
+			fmt.Println("tttok")
+		case fields[0] == "isready":
+			fmt.Println("readyok")
+		case fields[0] == "newgame":
+			fmt.Println("info newgame")
+		case fields[0] == "position":
+			board = fields[1]
+		case fields[0] == "go" && mode == "late":
+			fmt.Println("info", strings.Join(fields, " "))
+		case fields[0] == "go":
+			fmt.Println("info", strings.Join(fields, " "))
+			bestmove()
+		case fields[0] == "stop" && mode == "late":
+			bestmove()
+			mode = "first"
+		case fields[0] == "quit":
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+func startHelperEngine(ctx context.Context, mode string) (*ProcessEngine, error) {
+	os.Setenv("TTT_HELPER_ENGINE", mode)
+	defer os.Unsetenv("TTT_HELPER_ENGINE")
+	// under -race a process sleeps for a second before exiting, which is
+	// as long as Close waits for the engine to quit
+	gorace := os.Getenv("GORACE")
+	os.Setenv("GORACE", strings.TrimSpace(gorace+" atexit_sleep_ms=0"))
+	defer os.Setenv("GORACE", gorace)
+	return StartProcessEngine(ctx, os.Args[0], "-test.run=TestHelperEngine")
+}
+
+func helperEngine(t *testing.T, mode string) *ProcessEngine {
+	p, err := startHelperEngine(context.Background(), mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestProcessEngine(t *testing.T) {
+	p := helperEngine(t, "first")
+	assert.Equal(t, "first open", p.Name())
+	move, err := p.BestMove(context.Background(), bitboardOf("xo.", "...", "..."), "X")
+	assert.NoError(t, err)
+	assert.Equal(t, Move{XAxis: 2, YAxis: 0}, move)
+
+	// both play the first open square, so X wins on the diagonal
+	assert.NoError(t, p.NewGame())
+	assert.Equal(t, XWins, playEngines(t, p, FirstOpenEngine{}))
+	assert.NoError(t, p.Close())
+
+	_, err = p.BestMove(context.Background(), bitboardOf("...", "...", "..."), "X")
+	assert.Error(t, err)
+}
+
+func TestProcessEngineHandshake(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := startHelperEngine(ctx, "mute")
+	assert.Equal(t, ErrEngineHandshake, err)
+}
+
+func TestGoCommand(t *testing.T) {
+	assert.Equal(t, "go", goCommand(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var ms int
+	_, err := fmt.Sscanf(goCommand(ctx), "go movetime %d", &ms)
+	assert.NoError(t, err)
+	assert.InDelta(t, 980, ms, 20)
+}
+
+func TestProcessEngineTimeout(t *testing.T) {
+	p := helperEngine(t, "late")
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.BestMove(ctx, bitboardOf("...", "...", "..."), "X")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the answer to stop, a1, is thrown away and the engine answers go
+	// from now on
+	move, err := p.BestMove(context.Background(), bitboardOf("x..", "...", "..."), "O")
+	assert.NoError(t, err)
+	assert.Equal(t, Move{XAxis: 1, YAxis: 0}, move)
+}
+
+func TestEnginePlayer(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Minute, nil)
+	defer g.Close()
+	x := EnginePlayer{Player: Player{ID: "x", Bot: true}, Engine: NewMinimaxEngine()}
+	o := EnginePlayer{Player: Player{ID: "o", Bot: true}, Engine: helperEngine(t, "first")}
+	defer o.Engine.(*ProcessEngine).Close()
+	assert.NoError(t, g.AddPlayer(x.Player))
+	assert.NoError(t, g.AddPlayer(o.Player))
+
+	assert.Equal(t, ErrNotPlayersTurn, o.Move(context.Background(), g))
+	for i := 0; g.Status == InProgress; i++ {
+		p := []EnginePlayer{x, o}[i%2]
+		if !assert.NoError(t, p.Move(context.Background(), g)) {
+			return
+		}
+	}
+	assert.Equal(t, XWins, g.Status)
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotPlayersTurn is returned when asking a player to move out of turn
+var ErrNotPlayersTurn = errors.New("not the player's turn")
+
+// NewGamer is an Engine that wants to be told when a new game starts,
+// like ProcessEngine
+type NewGamer interface {
+	NewGame() error
+}
+
+// EnginePlayer is a player whose moves are chosen by an engine, such as
+// a ProcessEngine running a bot written in another language. Add Player
+// to the game like any other player, then call Move on its turns.
+type EnginePlayer struct {
+	Player Player
+	Engine Engine
+}
+
+// Move has the engine choose the player's move and places it. The engine
+// is given until the move deadline, or until ctx is done if sooner.
+func (p EnginePlayer) Move(ctx context.Context, g *Game) error {
+	state := g.State()
+	id := state.playerTurnId()
+	if id == nil || *id != p.Player.ID {
+		return ErrNotPlayersTurn
+	}
+	side, bb := state.Move, NewBitboard(state.Board)
+	if state.Deadline != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, *state.Deadline)
+		defer cancel()
+	}
+
+	// the player's first move of a game
+	if ng, ok := p.Engine.(NewGamer); ok && len(state.History) < 2 {
+		if err := ng.NewGame(); err != nil {
+			return err
+		}
+	}
+
+	move, err := p.Engine.BestMove(ctx, bb, side)
+	if err != nil {
+		return err
+	}
+	move.PlayerID = p.Player.ID
+	return g.PlacePiece(move)
+}
@@ -0,0 +1,67 @@
+package game
+
+import "time"
+
+// EventType names something that happened in the game
+type EventType string
+
+const (
+	// EventPlayerJoined is sent when a player is added to the game or the
+	// queue
+	EventPlayerJoined EventType = "player_joined"
+	// EventYourTurn is sent when a player's turn starts
+	EventYourTurn EventType = "your_turn"
+	// EventGameOver is sent when a game is won or drawn
+	EventGameOver EventType = "game_over"
+)
+
+// Event is something that happened in the game, for telling services
+// outside it
+type Event struct {
+	Type EventType `json:"type"`
+	Date time.Time `json:"date"`
+	// Player joined, or is to move
+	Player *Player `json:"player,omitempty"`
+	// Position is where a player joined: X, O or queue
+	Position string `json:"position,omitempty"`
+	// Deadline is when a move will be made for the player to move
+	Deadline *time.Time `json:"move_deadline,omitempty"`
+	// Result and RecordID are set when a game is over
+	Result   Status `json:"result,omitempty"`
+	RecordID string `json:"record_id,omitempty"`
+}
+
+// OnEvent sets the function called with every event. It is called while
+// the game is being changed, so it must be quick and must not call back
+// into the game.
+func (g *Game) OnEvent(fn func(Event)) {
+	g.lock()
+	defer g.unlock()
+	g.onEvent = fn
+}
+
+func (g *Game) emit(e Event) {
+	if g.onEvent == nil {
+		return
+	}
+	e.Date = time.Now().UTC()
+	g.onEvent(e)
+}
+
+// playerToMove returns a copy of the player to move, if a game is in
+// progress
+func (g *Game) playerToMove() *Player {
+	if g.Status != InProgress {
+		return nil
+	}
+	var p Player
+	switch {
+	case g.Move == "X" && g.X != nil:
+		p = *g.X
+	case g.Move == "O" && g.O != nil:
+		p = *g.O
+	default:
+		return nil
+	}
+	return &p
+}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnEvent(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	var events []Event
+	g.OnEvent(func(e Event) { events = append(events, e) })
+
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDQ"}))
+	for _, m := range []Move{
+		{PlayerID: "testIDX", XAxis: 0, YAxis: 0},
+		{PlayerID: "testIDO", XAxis: 0, YAxis: 1},
+		{PlayerID: "testIDX", XAxis: 1, YAxis: 0},
+		{PlayerID: "testIDO", XAxis: 1, YAxis: 1},
+		{PlayerID: "testIDX", XAxis: 2, YAxis: 0},
+	} {
+		assert.NoError(t, g.PlacePiece(m))
+	}
+	g.Close()
+
+	type summary struct {
+		Type     EventType
+		PlayerID string
+		Position string
+		Result   Status
+	}
+	var got []summary
+	for _, e := range events {
+		s := summary{Type: e.Type, Position: e.Position, Result: e.Result}
+		if e.Player != nil {
+			s.PlayerID = e.Player.ID
+		}
+		got = append(got, s)
+		assert.False(t, e.Date.IsZero())
+	}
+	assert.Equal(t, []summary{
+		{Type: EventPlayerJoined, PlayerID: "testIDX", Position: "X"},
+		{Type: EventPlayerJoined, PlayerID: "testIDO", Position: "O"},
+		{Type: EventYourTurn, PlayerID: "testIDX"},
+		{Type: EventPlayerJoined, PlayerID: "testIDQ", Position: "queue"},
+		{Type: EventYourTurn, PlayerID: "testIDO"},
+		{Type: EventYourTurn, PlayerID: "testIDX"},
+		{Type: EventYourTurn, PlayerID: "testIDO"},
+		{Type: EventYourTurn, PlayerID: "testIDX"},
+		{Type: EventGameOver, Result: XWins},
+	}, got)
+	assert.Equal(t, "1", events[len(events)-1].RecordID)
+	assert.NotNil(t, events[2].Deadline)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -24,9 +25,6 @@ const (
 	blank  Piece = 0
 	xPiece Piece = -1
 	oPiece Piece = 1
-
-	xWins = -3
-	oWins = 3
 )
 
 // Board represents a game board that holds piece positions
@@ -40,6 +38,8 @@ type Board [3][3]Piece
 type Player struct {
 	ID   string  `json:"id"`
 	Name *string `json:"name"`
+	// Bot is set for players whose moves are made by a program
+	Bot bool `json:"bot,omitempty"`
 }
 
 // Status is a game status
@@ -81,13 +81,9 @@ const (
 	InProgress          Status = "InProgress"
 )
 
-func (s Status) ptr() *string {
-	x := string(s)
-	return &x
-}
-
 // Game represents the entire Game state, including current X and Y,
-// the board, and the queue.
+// the board, and the queue. Its methods are safe to call from several
+// goroutines; use State to read the exported fields of a game in play.
 type Game struct {
 	Board  *Board   `json:"board"`
 	Queue  []Player `json:"queue"`
@@ -95,12 +91,46 @@ type Game struct {
 	O      *Player  `json:"player_o"`
 	Move   string   `json:"move,omitempty"`
 	Status Status   `json:"status"`
-	log    *logrus.Entry
+	// History is every move placed on the current board, in order
+	History []Move `json:"history,omitempty"`
+	// Deadline is when the player to move will have a move made for them
+	Deadline *time.Time `json:"move_deadline,omitempty"`
+	// Paused is set while an admin has stopped the move timeout
+	Paused bool `json:"paused,omitempty"`
+	// Announcement is the last message broadcast by an admin
+	Announcement *Announcement `json:"announcement,omitempty"`
+	log          *logrus.Entry
 
 	UpdatedCh chan<- Game `json:"-"`
 	timeout   time.Duration
 
-	resetTimeoutCh, stopTimeoutCh chan struct{}
+	// mu guards the game. Exported methods take it, unexported ones
+	// expect the caller to hold it.
+	mu *sync.Mutex
+
+	// timer counts down the player to move, and the pause between games
+	timer         *turnTimer
+	nextGameDelay time.Duration
+	// closed is set by Close
+	closed bool
+	// done is closed by Close, ending sends on UpdatedCh that were never
+	// received
+	done chan struct{}
+	// turn counts the countdowns started, so one that fires after it was
+	// replaced can tell
+	turn int
+	// turnStarted is when the player to move started their turn
+	turnStarted time.Time
+
+	metrics Metrics
+	onEvent func(Event)
+
+	records   []Record
+	recordSeq int
+
+	watch *watchers
+
+	moderation *moderation
 }
 
 // New returns a new game instance.
@@ -125,29 +155,148 @@ func New(logger *logrus.Entry, timeout time.Duration, ch chan<- Game) *Game {
 		UpdatedCh: ch,
 		log:       logger,
 		timeout:   timeout,
+		mu:        &sync.Mutex{},
+		done:      make(chan struct{}),
+
+		timer:         &turnTimer{},
+		nextGameDelay: 3 * time.Second,
 
-		resetTimeoutCh: make(chan struct{}),
-		stopTimeoutCh:  make(chan struct{}),
+		watch:      newWatchers(),
+		moderation: newModeration(),
+		metrics:    nopMetrics{},
 	}
 	return g
 }
 
+// lock takes the game's lock, creating it for games that were not made
+// with New
+func (g *Game) lock() {
+	if g.mu == nil {
+		g.mu = &sync.Mutex{}
+	}
+	g.mu.Lock()
+}
+
+func (g *Game) unlock() {
+	g.mu.Unlock()
+}
+
+// jsonGame is Game without its methods, so encoding one doesn't take the
+// lock
+type jsonGame Game
+
 func (g *Game) String() string {
 	if g == nil {
 		return ""
 	}
+	g.lock()
+	defer g.unlock()
+	return g.string()
+}
 
-	bs, err := json.Marshal(g)
+// string is String for callers holding the lock
+func (g *Game) string() string {
+	bs, err := json.Marshal((*jsonGame)(g))
 	if err != nil {
 		return ""
 	}
 	return string(bs)
 }
 
+// MarshalJSON encodes the game under its lock
+func (g *Game) MarshalJSON() ([]byte, error) {
+	g.lock()
+	defer g.unlock()
+	return json.Marshal((*jsonGame)(g))
+}
+
+// State returns a copy of the game that shares nothing with it, so its
+// fields can be read while the game goes on
+func (g *Game) State() Game {
+	g.lock()
+	defer g.unlock()
+	return g.snapshot()
+}
+
+// snapshot is State for callers holding the lock
+func (g *Game) snapshot() Game {
+	s := Game{
+		Move:    g.Move,
+		Status:  g.Status,
+		History: append([]Move(nil), g.History...),
+		Paused:  g.Paused,
+		log:     g.log,
+	}
+	if g.Board != nil {
+		b := *g.Board
+		s.Board = &b
+	}
+	if g.Queue != nil {
+		s.Queue = append([]Player{}, g.Queue...)
+	}
+	if g.X != nil {
+		x := *g.X
+		s.X = &x
+	}
+	if g.O != nil {
+		o := *g.O
+		s.O = &o
+	}
+	if g.Deadline != nil {
+		d := *g.Deadline
+		s.Deadline = &d
+	}
+	if g.Announcement != nil {
+		a := *g.Announcement
+		s.Announcement = &a
+	}
+	return s
+}
+
+// Players returns how many players are playing and how many are waiting
+// in the queue
+func (g *Game) Players() (playing, queued int) {
+	g.lock()
+	defer g.unlock()
+	if g.X != nil {
+		playing++
+	}
+	if g.O != nil {
+		playing++
+	}
+	return playing, len(g.Queue)
+}
+
+// SetUpdatedCh sets the channel sent the game after every change
+func (g *Game) SetUpdatedCh(ch chan<- Game) {
+	g.lock()
+	defer g.unlock()
+	g.UpdatedCh = ch
+}
+
+// closing returns the channel closed by Close, creating it for games
+// that were not made with New
+func (g *Game) closing() chan struct{} {
+	if g.done == nil {
+		g.done = make(chan struct{})
+	}
+	return g.done
+}
+
+// update sends a copy of the game on UpdatedCh and to its watchers. The
+// caller holds the lock.
 func (g *Game) update() {
+	state := g.snapshot()
 	if g.UpdatedCh != nil {
-		go func() { g.UpdatedCh <- *g }()
+		// the receiver may be gone for good once the game is closed
+		go func(ch chan<- Game, done <-chan struct{}) {
+			select {
+			case ch <- state:
+			case <-done:
+			}
+		}(g.UpdatedCh, g.closing())
 	}
+	g.watch.notify(state)
 }
 
 // WriteTo ...
@@ -155,8 +304,70 @@ func (g *Game) WriteTo(w io.Writer) error {
 	return json.NewEncoder(w).Encode(g)
 }
 
+// Reset stops the timer and empties the game in place: no board moves,
+// players or queue. Recorded games, bans, name rules and watchers are
+// kept, and watchers are sent the empty game.
+func (g *Game) Reset() {
+	g.lock()
+	defer g.unlock()
+	defer g.update()
+	g.log.Info("resetting game")
+	g.stopTimeout()
+	g.clearBoard()
+	g.Queue = []Player{}
+	g.X, g.O = nil, nil
+	g.Move = ""
+	g.Status = InsufficientPlayers
+	g.Deadline = nil
+	g.Paused = false
+	g.Announcement = nil
+}
+
+// Close stops the timer for good and ends every watch, for shutting
+// down. Moves can still be made so requests in flight can finish, but
+// players are no longer timed and the next game never starts.
+func (g *Game) Close() {
+	g.lock()
+	g.log.Info("closing game")
+	if !g.closed {
+		close(g.closing())
+	}
+	g.closed = true
+	g.Deadline = nil
+	g.turn++
+	timer, watch := g.turnTimer(), g.watch
+	g.unlock()
+
+	// a countdown that has fired waits for the lock before it sees the
+	// game is closed, so the timer is closed without holding it
+	timer.close()
+	if watch != nil {
+		watch.close()
+	}
+}
+
+// Clear empties the game.
+//
+// Deprecated: use Reset, Clear is kept for existing callers.
 func (g *Game) Clear() {
-	g = New(g.log, g.timeout, g.UpdatedCh)
+	g.Reset()
+}
+
+// Restart clears the board and starts the game again with the same
+// players, X to move
+func (g *Game) Restart() {
+	g.lock()
+	defer g.unlock()
+	defer g.update()
+	g.log.Info("restarting game")
+	g.stopTimeout()
+	g.clearBoard()
+	g.Move = "X"
+	g.updateStatus()
+	if g.Status == InProgress {
+		g.startTurn()
+		g.observe().GameStarted()
+	}
 }
 
 func (g *Game) clearBoard() {
@@ -166,6 +377,7 @@ func (g *Game) clearBoard() {
 		{blank, blank, blank}})
 
 	g.Board = &b
+	g.History = nil
 }
 
 // advanceQueue takes a player to add to the back of the queue and
@@ -183,6 +395,13 @@ func (g *Game) advanceQueue(loser *Player) (next *Player) {
 
 // NextGame advances to the next game, adjusting the queue and the board.
 func (g *Game) NextGame() error {
+	g.lock()
+	defer g.unlock()
+	return g.nextGame()
+}
+
+// nextGame is NextGame for callers holding the lock
+func (g *Game) nextGame() error {
 	switch g.Status {
 	case XWins:
 		g.Move = "O"
@@ -221,16 +440,17 @@ func (g *Game) NextGame() error {
 	case InsufficientPlayers:
 	default:
 		// do nothing, method called incorrectly
-		g.log.WithField("game_state", g.String()).Info("no caught state in NextGame call")
+		g.log.WithField("game_state", g.string()).Info("no caught state in NextGame call")
 		return ErrGameInProgress
 	}
 
 	defer g.update()
 	g.stopTimeout()
-	if g.O != nil && g.X != nil {
-		g.setTimeout(g.timeout)
-	}
 	g.updateStatus()
+	if g.Status == InProgress {
+		g.startTurn()
+		g.observe().GameStarted()
+	}
 	return nil
 }
 
@@ -260,66 +480,19 @@ func (g *Game) firstOpenPositionsOnBoard() (x, y int, err error) {
 	return -1, -1, errors.New("no empty spots")
 }
 
-func (g *Game) stopTimeout() { go func() { g.stopTimeoutCh <- struct{}{} }() }
-
-func (g *Game) resetTimeout() { go func() { g.resetTimeoutCh <- struct{}{} }() }
-
-// setTimeouts job is to make a random move after d duration if none
-// has been made, advancing the game. If resetTimeout is activated,
-// the loop starts over. If stopTimeout is called, setTimeout(d) must
-// be called once more to start the loop again
-func (g *Game) setTimeout(d time.Duration) {
-	g.log.Info("starting timeout")
-	go func() {
-		for {
-			select {
-			case <-time.After(d):
-				g.log.Info("timeout received")
-				// took too long, timeout and make a random move
-				id := g.playerTurnId()
-				if id == nil {
-					g.log.
-						WithError(errors.New("could not find current player id")).
-						Error("unable to make automatic move")
-					continue
-				}
-				x, y, err := g.firstOpenPositionsOnBoard()
-				if err != nil {
-					g.log.WithError(err).Error("unable to calculate random move")
-					continue
-				}
-
-				g.log.WithFields(logrus.Fields{
-					"x":  x,
-					"y":  y,
-					"id": *id,
-				}).Info("placing move for user")
-
-				if err := g.PlacePiece(Move{
-					PlayerID: *id,
-					XAxis:    x, YAxis: y,
-				}); err != nil {
-					g.log.WithError(err).Error("unable to place random move")
-				}
-
-			case <-g.resetTimeoutCh:
-				g.log.Info("resetting timeout")
-				continue
-
-			case <-g.stopTimeoutCh:
-				g.log.Info("stopping timeout")
-				return
-			}
-		}
-	}()
-}
-
 func (g *Game) updateStatus() {
 	g.Status = g.status()
 }
 
 // PlacePiece places p at xLoc/yLoc on board
 func (g *Game) PlacePiece(move Move) error {
+	g.lock()
+	defer g.unlock()
+	return g.placePiece(move, false)
+}
+
+// placePiece places a move, auto is set for moves made on timeout
+func (g *Game) placePiece(move Move, auto bool) error {
 	logCtx := g.log.WithFields(logrus.Fields{
 		"x":         move.XAxis,
 		"y":         move.YAxis,
@@ -330,6 +503,10 @@ func (g *Game) PlacePiece(move Move) error {
 		logCtx.WithField("status", g.Status).Error("invalid move")
 		return ErrInvalidMove
 	}
+	if move.XAxis < 0 || move.XAxis >= len(g.Board[0]) || move.YAxis < 0 || move.YAxis >= len(g.Board) {
+		logCtx.Error("move off the board")
+		return ErrInvalidMove
+	}
 
 	defer g.update()
 
@@ -344,9 +521,10 @@ func (g *Game) PlacePiece(move Move) error {
 			return ErrInvalidMove
 		}
 		g.Board[move.YAxis][move.XAxis] = xPiece
+		g.History = append(g.History, move)
 		logCtx.WithField("move", g.Move).Info("move placed")
 		g.Move = "O"
-		g.resetTimeout()
+		g.observe().MovePlaced(time.Since(g.turnStarted), auto)
 
 	case "O":
 		if g.O.ID != move.PlayerID {
@@ -358,9 +536,10 @@ func (g *Game) PlacePiece(move Move) error {
 			return ErrInvalidMove
 		}
 		g.Board[move.YAxis][move.XAxis] = oPiece
+		g.History = append(g.History, move)
 		logCtx.WithField("move", g.Move).Info("move placed")
 		g.Move = "X"
-		g.resetTimeout()
+		g.observe().MovePlaced(time.Since(g.turnStarted), auto)
 
 	default:
 	}
@@ -368,24 +547,52 @@ func (g *Game) PlacePiece(move Move) error {
 	g.updateStatus()
 	switch g.Status {
 	case XWins, OWins, Cats:
-		logCtx.Info("game over, refreshing board")
-		go func() {
-			<-time.After(3 * time.Second)
-			if err := g.NextGame(); err != nil {
-				logCtx.Errorf("error advancing: %s", err)
-			} else {
-				logCtx.WithField("status", g.Status.String()).Info("board refreshed")
-			}
-		}()
+		g.gameOver(logCtx)
+	case InProgress:
+		g.startTurn()
 	}
 	return nil
 }
 
+// gameOver records the finished game and starts the next one after a
+// short pause so players can see the final board
+func (g *Game) gameOver(logCtx *logrus.Entry) {
+	logCtx.Info("game over, refreshing board")
+	id := g.record()
+	g.observe().GameFinished(g.Status)
+	g.emit(Event{Type: EventGameOver, Result: g.Status, RecordID: id})
+	g.Deadline = nil
+	// the timer replaces the countdown, so resetting the game also
+	// cancels the next game
+	g.turn++
+	turn := g.turn
+	g.turnTimer().start(g.nextGameDelay, func() {
+		g.lock()
+		defer g.unlock()
+		// the game may have been changed while the timer fired
+		if g.closed || turn != g.turn {
+			return
+		}
+		if err := g.nextGame(); err != nil {
+			logCtx.Errorf("error advancing: %s", err)
+		} else {
+			logCtx.WithField("status", g.Status.String()).Info("board refreshed")
+		}
+	})
+}
+
 // AddPlayer adds a player to an empty position, or the bottom of the queue
 func (g *Game) AddPlayer(p Player) error {
+	g.lock()
+	defer g.unlock()
 	defer g.update()
 	logCtx := g.log.WithField("player_id", p.ID)
 
+	if err := g.checkPlayer(p); err != nil {
+		logCtx.WithError(err).Error("player rejected")
+		return err
+	}
+
 	for _, queued := range g.Queue {
 		if p.ID == queued.ID {
 			logCtx.Error("player already registered")
@@ -402,12 +609,19 @@ func (g *Game) AddPlayer(p Player) error {
 		// declaring function here for logCtx
 		logCtx.Info("game starting")
 		g.Status = InProgress
-		g.setTimeout(g.timeout)
+		g.startTurn()
+		g.observe().GameStarted()
+	}
+
+	joined := func(position string) {
+		player := p
+		g.emit(Event{Type: EventPlayerJoined, Player: &player, Position: position})
 	}
 
 	if g.X == nil {
 		logCtx.Info("player placed as player X")
 		g.X = &p
+		joined("X")
 		if g.O == nil {
 			g.Move = "X"
 		} else {
@@ -416,6 +630,7 @@ func (g *Game) AddPlayer(p Player) error {
 	} else if g.O == nil {
 		logCtx.Info("player placed as player O")
 		g.O = &p
+		joined("O")
 		if g.X == nil {
 			g.Move = "O"
 		} else {
@@ -424,27 +639,38 @@ func (g *Game) AddPlayer(p Player) error {
 	} else {
 		logCtx.Info("player placed in queue")
 		g.Queue = append(g.Queue, p)
+		joined("queue")
 	}
 	return nil
 }
 
 // UpdatePlayer sets or clears the player
 func (g *Game) UpdatePlayer(p Player) error {
+	g.lock()
+	defer g.unlock()
 	defer g.update()
 	g.log.WithField("id", p.ID).WithField("name", p.Name).Info("Updating player")
+	if err := g.checkPlayer(p); err != nil {
+		g.log.WithError(err).Error("player update rejected")
+		return err
+	}
+	// a player can't make themselves a bot, or stop being one
 	if g.X != nil && g.X.ID == p.ID {
+		p.Bot = g.X.Bot
 		g.X = &p
 		g.log.Info("Updated X")
 		return nil
 	}
 
 	if g.O != nil && g.O.ID == p.ID {
+		p.Bot = g.O.Bot
 		g.O = &p
 		g.log.Info("Updated O")
 		return nil
 	}
 	for i := range g.Queue {
 		if g.Queue[i].ID == p.ID {
+			p.Bot = g.Queue[i].Bot
 			g.Queue[i] = p
 			g.log.Infof("Updated queue position %v", i)
 			return nil
@@ -457,20 +683,27 @@ func (g *Game) UpdatePlayer(p Player) error {
 // RemovePlayer removes a player from the queue and returns a 'ErrPlayerNotFound'
 // error if no player with the supplied ID was found
 func (g *Game) RemovePlayer(id string) error {
+	g.lock()
+	defer g.unlock()
+	return g.removePlayer(id)
+}
+
+// removePlayer is RemovePlayer for callers holding the lock
+func (g *Game) removePlayer(id string) error {
 	defer g.update()
 	logCtx := g.log.WithField("player_id", id)
 	if g.X != nil && g.X.ID == id {
 		g.X = nil
 		logCtx.Info("player removed from position X")
 		g.clearBoard()
-		return g.NextGame()
+		return g.nextGame()
 	}
 
 	if g.O != nil && g.O.ID == id {
 		g.O = nil
 		logCtx.Info("player removed from position O")
 		g.clearBoard()
-		return g.NextGame()
+		return g.nextGame()
 	}
 
 	idx := -1
@@ -491,16 +724,6 @@ func (g *Game) RemovePlayer(id string) error {
 	return nil
 }
 
-func checkScore(s int) *string {
-	switch s {
-	case oWins:
-		return OWins.ptr()
-	case xWins:
-		return XWins.ptr()
-	}
-	return nil
-}
-
 func (g *Game) status() Status {
 	if g.X == nil || g.O == nil {
 		return InsufficientPlayers
@@ -509,51 +732,5 @@ func (g *Game) status() Status {
 	if g.Board == nil {
 		return NoBoard
 	}
-
-	// if the board total is 0, it is X's move (if there are spots left)
-	// else, it is Y's move
-	boardTotal := 0
-
-	// if movesLeft is 0, game is over
-	movesLeft := 9
-
-	colSums := [...]int{0, 0, 0}
-
-	for _, row := range g.Board {
-		rowSum := 0
-		for i, col := range row {
-			if col != 0 {
-				movesLeft--
-			}
-			rowSum += int(col)
-			colSums[i] += int(col)
-		}
-
-		boardTotal += rowSum
-		if s := checkScore(boardTotal); s != nil {
-			return Status(*s)
-		}
-	}
-
-	for _, col := range colSums {
-		if s := checkScore(col); s != nil {
-			return Status(*s)
-		}
-	}
-
-	diagonal := g.Board[0][0] + g.Board[1][1] + g.Board[2][2]
-	if s := checkScore(int(diagonal)); s != nil {
-		return Status(*s)
-	}
-
-	diagonal = g.Board[2][0] + g.Board[1][1] + g.Board[0][2]
-	if s := checkScore(int(diagonal)); s != nil {
-		return Status(*s)
-	}
-
-	if movesLeft == 0 {
-		return Cats
-	}
-
-	return InProgress
+	return NewBitboard(g.Board).Status()
 }
@@ -3,7 +3,9 @@ package game
 import (
 	"encoding/json"
 	"fmt"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -120,6 +122,18 @@ func TestGameStatus(t *testing.T) {
 			},
 			expected: XWins,
 		},
+		{
+			name: "Returns in progress when rows only add up to a line",
+			game: Game{
+				log: logrus.WithField("test", true),
+				Board: &Board{
+					{xPiece, xPiece, oPiece},
+					{xPiece, oPiece, blank},
+					{blank, blank, blank}},
+				X: &Player{},
+				O: &Player{}},
+			expected: InProgress,
+		},
 		{
 			name: "Returns cats for no winner",
 			game: Game{
@@ -186,7 +200,8 @@ func TestNextGame(t *testing.T) {
 					{ID: "TestIDFoo"},
 					{ID: "TestIDBar"},
 				},
-				log: logrus.WithField("test", true),
+				log:     logrus.WithField("test", true),
+				timeout: time.Hour,
 			},
 			compareGame: func(t *testing.T, actual *Game) {
 				if actual.X.ID != "testIDX" {
@@ -223,11 +238,12 @@ func TestNextGame(t *testing.T) {
 					1, 1, -1,
 					-1, 1, 1,
 				),
-				Status: XWins,
-				O:      &Player{ID: "testIDO"},
-				X:      &Player{ID: "testIDX"},
-				Queue:  []Player{},
-				log:    logrus.WithField("test", true),
+				Status:  XWins,
+				O:       &Player{ID: "testIDO"},
+				X:       &Player{ID: "testIDX"},
+				Queue:   []Player{},
+				log:     logrus.WithField("test", true),
+				timeout: time.Hour,
 			},
 			compareGame: func(t *testing.T, actual *Game) {
 				if actual.X.ID != "testIDX" {
@@ -263,7 +279,8 @@ func TestNextGame(t *testing.T) {
 					{ID: "TestIDFoo"},
 					{ID: "TestIDBar"},
 				},
-				log: logrus.WithField("test", true),
+				log:     logrus.WithField("test", true),
+				timeout: time.Hour,
 			},
 			compareGame: func(t *testing.T, actual *Game) {
 				if actual.X.ID == "testIDX" && actual.O.ID == "testIDO" {
@@ -298,3 +315,202 @@ func TestNextGame(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordReplay(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+
+	moves := []Move{
+		{PlayerID: "testIDX", XAxis: 0, YAxis: 0},
+		{PlayerID: "testIDO", XAxis: 0, YAxis: 1},
+		{PlayerID: "testIDX", XAxis: 1, YAxis: 0},
+		{PlayerID: "testIDO", XAxis: 1, YAxis: 1},
+		{PlayerID: "testIDX", XAxis: 2, YAxis: 0},
+	}
+	for _, m := range moves {
+		assert.NoError(t, g.PlacePiece(m))
+	}
+	state := g.State()
+	assert.Equal(t, XWins, state.Status)
+
+	records := g.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record but got %v", len(records))
+	}
+
+	rec, err := g.Record(records[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, XWins, rec.Result)
+	assert.Equal(t, moves, rec.Moves)
+
+	positions, err := rec.Positions()
+	assert.NoError(t, err)
+	assert.Len(t, positions, len(moves)+1)
+	assert.Equal(t, Board{}, positions[0])
+	assert.Equal(t, *state.Board, positions[len(moves)])
+
+	board, err := rec.Position(2)
+	assert.NoError(t, err)
+	assert.Equal(t, tstBoardPtr(
+		-1, 0, 0,
+		1, 0, 0,
+		0, 0, 0,
+	), board)
+
+	_, err = rec.Position(len(moves) + 1)
+	assert.Equal(t, ErrInvalidPly, err)
+
+	_, err = g.Record("missing")
+	assert.Equal(t, ErrRecordNotFound, err)
+}
+
+func TestWatch(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	updates, stop := g.Watch()
+
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+
+	// only the latest state is kept for a slow watcher
+	state := <-updates
+	assert.Equal(t, "testIDO", state.O.ID)
+
+	stop()
+	_, ok := <-updates
+	assert.False(t, ok, "expected updates to be closed after stop")
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDFoo"}))
+}
+
+func TestReset(t *testing.T) {
+	g := New(logrus.WithField("test", true), 20*time.Millisecond, nil)
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDQ"}))
+	assert.NoError(t, g.Ban("testIDBanned"))
+	assert.NoError(t, g.ForceResult(XWins))
+	updates, stop := g.Watch()
+	defer stop()
+
+	g.Reset()
+	reset := g.State()
+	assert.Equal(t, &Board{}, reset.Board)
+	assert.Nil(t, reset.X)
+	assert.Nil(t, reset.O)
+	assert.Empty(t, reset.Queue)
+	assert.Empty(t, reset.History)
+	assert.Equal(t, InsufficientPlayers, reset.Status)
+	assert.Len(t, g.Records(), 1, "expected recorded games to be kept")
+	assert.True(t, g.Banned("testIDBanned"), "expected bans to be kept")
+
+	state := <-updates
+	assert.Equal(t, InsufficientPlayers, state.Status)
+
+	// the stopped timer must not make moves or set a deadline, and must
+	// not stop the timer of the next game
+	<-time.After(100 * time.Millisecond)
+	assert.Nil(t, g.State().Deadline)
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	<-time.After(100 * time.Millisecond)
+	assert.NotEmpty(t, g.State().History, "expected the new game to time out")
+}
+
+func TestPlayers(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	tCases := []struct {
+		id              string
+		playing, queued int
+	}{
+		{id: "testIDX", playing: 1},
+		{id: "testIDO", playing: 2},
+		{id: "testIDQ", playing: 2, queued: 1},
+	}
+	for _, tc := range tCases {
+		assert.NoError(t, g.AddPlayer(Player{ID: tc.id}))
+		playing, queued := g.Players()
+		assert.Equal(t, tc.playing, playing, tc.id)
+		assert.Equal(t, tc.queued, queued, tc.id)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.PlacePiece(Move{PlayerID: "testIDX", XAxis: 1, YAxis: 1}))
+
+	g.Restart()
+	state := g.State()
+	assert.Equal(t, &Board{}, state.Board)
+	assert.Empty(t, state.History)
+	assert.Equal(t, "X", state.Move)
+	assert.Equal(t, InProgress, state.Status)
+	assert.Equal(t, "testIDX", state.X.ID)
+	assert.Equal(t, "testIDO", state.O.ID)
+}
+
+func TestClose(t *testing.T) {
+	g := New(logrus.WithField("test", true), 20*time.Millisecond, nil)
+	updates, stop := g.Watch()
+	defer stop()
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+
+	g.Close()
+	for range updates {
+		// drain the last state, the channel must then be closed
+	}
+	late, _ := g.Watch()
+	_, ok := <-late
+	assert.False(t, ok, "expected watches after Close to end straight away")
+
+	assert.NoError(t, g.PlacePiece(Move{PlayerID: "testIDX", XAxis: 1, YAxis: 1}), "expected moves in flight to finish")
+	<-time.After(100 * time.Millisecond)
+	state := g.State()
+	assert.Len(t, state.History, 1, "expected no timeouts after Close")
+	assert.Nil(t, state.Deadline)
+}
+
+func TestCloseEndsUpdates(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// nobody reads the channel, as when the store has stopped
+	g := New(logrus.WithField("test", true), time.Hour, make(chan Game))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.PlacePiece(Move{PlayerID: "testIDX", XAxis: 1, YAxis: 1}))
+	assert.True(t, runtime.NumGoroutine() > before, "expected updates waiting to be sent")
+
+	g.Close()
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		<-time.After(time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= before, "expected no updates left waiting, had %d, now %d", before, runtime.NumGoroutine())
+}
+
+func TestPlacePieceOffBoard(t *testing.T) {
+	tCases := []struct {
+		name string
+		move Move
+	}{
+		{name: "x too big", move: Move{PlayerID: "testIDX", XAxis: 3}},
+		{name: "y too big", move: Move{PlayerID: "testIDX", YAxis: 9}},
+		{name: "x negative", move: Move{PlayerID: "testIDX", XAxis: -1}},
+		{name: "y negative", move: Move{PlayerID: "testIDX", YAxis: -1}},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := New(logrus.WithField("test", true), time.Hour, nil)
+			defer g.Close()
+			assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+			assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+
+			assert.Equal(t, ErrInvalidMove, g.PlacePiece(tc.move))
+			state := g.State()
+			assert.Empty(t, state.History)
+			assert.Equal(t, "X", state.Move)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrGameClosed   = errors.New("game closed")
+	ErrTimerStopped = errors.New("no move deadline for the game in progress")
+	ErrGameStuck    = errors.New("move deadline passed without a move")
+)
+
+// deadlineGrace is how long past the move deadline the timeout move may
+// take before the game is considered stuck
+const deadlineGrace = 2 * time.Second
+
+// Check returns an error when the game is not running normally: it has
+// been closed, a timed out player has not had a move made for them, or a
+// game is in progress without a move deadline.
+func (g *Game) Check() error {
+	g.lock()
+	defer g.unlock()
+	return g.check()
+}
+
+// check is Check for callers holding the lock
+func (g *Game) check() error {
+	if g.closed {
+		return ErrGameClosed
+	}
+	if g.Deadline != nil && time.Since(*g.Deadline) > deadlineGrace {
+		return ErrGameStuck
+	}
+	// the deadline is set with the countdown and cleared with it, under
+	// the lock, so unlike the timer it doesn't blink off while a timeout
+	// move is waiting to be made
+	if g.Status == InProgress && !g.Paused && g.Deadline == nil {
+		return ErrTimerStopped
+	}
+	return nil
+}
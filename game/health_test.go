@@ -0,0 +1,84 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	tCases := []struct {
+		name     string
+		setup    func(g *Game)
+		expected error
+	}{
+		{
+			name:  "a game waiting for players is fine",
+			setup: func(g *Game) {},
+		},
+		{
+			name: "a game in progress is fine",
+			setup: func(g *Game) {
+				g.AddPlayer(Player{ID: "testIDX"})
+				g.AddPlayer(Player{ID: "testIDO"})
+			},
+		},
+		{
+			name: "a paused game is fine",
+			setup: func(g *Game) {
+				g.AddPlayer(Player{ID: "testIDX"})
+				g.AddPlayer(Player{ID: "testIDO"})
+				g.PauseTimeout()
+			},
+		},
+		{
+			name: "a game in progress without a deadline is not",
+			setup: func(g *Game) {
+				g.AddPlayer(Player{ID: "testIDX"})
+				g.AddPlayer(Player{ID: "testIDO"})
+				g.stopTimeout()
+			},
+			expected: ErrTimerStopped,
+		},
+		{
+			name: "a deadline long past is not",
+			setup: func(g *Game) {
+				g.AddPlayer(Player{ID: "testIDX"})
+				g.AddPlayer(Player{ID: "testIDO"})
+				past := time.Now().Add(-time.Minute)
+				g.Deadline = &past
+			},
+			expected: ErrGameStuck,
+		},
+		{
+			name:     "a closed game is not",
+			setup:    func(g *Game) { g.Close() },
+			expected: ErrGameClosed,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := New(logrus.WithField("test", true), time.Hour, nil)
+			tc.setup(g)
+			assert.Equal(t, tc.expected, g.Check())
+			g.Close()
+		})
+	}
+}
+
+func TestCheckWhileTimeoutWaits(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Millisecond, nil)
+	defer g.Close()
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+
+	// the countdown fires and its move waits for the lock
+	g.lock()
+	<-time.After(20 * time.Millisecond)
+	assert.False(t, g.turnTimer().running())
+	assert.NoError(t, g.check(), "expected a game with a timeout move on the way to be fine")
+	g.unlock()
+}
@@ -0,0 +1,358 @@
+package game
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMCTSIterations is the playouts made per move when an
+	// MCTSEngine has no other limit
+	defaultMCTSIterations = 10000
+	// mctsExploration is the UCT exploration constant, sqrt 2
+	mctsExploration = math.Sqrt2
+)
+
+// MCTSEngine chooses moves by Monte Carlo tree search: it plays out
+// random games from the position, spending more of them on the moves
+// that do best so far, and plays the move it tried most. Unlike
+// MinimaxEngine it needs no knowledge of the game beyond its rules, so
+// it plays any Variant, such as 15 by 15 with 5 in a row, through
+// BestPositionMove and AnalyzePosition. Its strength depends on the
+// playouts it is given rather than on the size of the board. Only moves
+// within two squares of a piece are searched, which on the standard
+// board is every move.
+//
+// Playouts run on Workers goroutines sharing one tree. The part of the
+// tree under the move played is kept, so the next move starts from the
+// playouts already made.
+type MCTSEngine struct {
+	// Iterations caps the playouts made per move. With neither Iterations
+	// nor MoveTime, defaultMCTSIterations are made.
+	Iterations int
+	// MoveTime caps the time spent per move. The search also stops when
+	// the context passed to BestMove is done.
+	MoveTime time.Duration
+	// Workers is how many goroutines make playouts, defaulting to
+	// GOMAXPROCS
+	Workers int
+
+	// searchMu makes sure only one search runs at a time
+	searchMu sync.Mutex
+	// mu guards root, the nodes under it and board, the root's position
+	mu    sync.Mutex
+	root  *mctsNode
+	board *mnkBoard
+	seed  int64
+}
+
+// NewMCTSEngine returns an MCTSEngine whose playouts are decided by seed
+func NewMCTSEngine(seed int64) *MCTSEngine {
+	return &MCTSEngine{seed: seed}
+}
+
+// mctsNode is a position in the search tree. Nodes hold the move that
+// reaches them rather than the board, which is replayed from the root.
+type mctsNode struct {
+	parent *mctsNode
+	// square was played to reach the node, by mover
+	square   int
+	mover    Piece
+	children []*mctsNode
+	// untried are the squares without a child yet
+	untried []int16
+	// status is the result once the game is over at the node
+	status Status
+	// visits counts playouts through the node, including ones still
+	// running so parallel workers spread out. wins counts them for
+	// mover, a draw as half.
+	visits float64
+	wins   float64
+}
+
+// newMCTSNode returns the node for b, reached by playing square. Below
+// the root a move that wins straight away is the only one tried, so a
+// move that lets the other side win is found out on its first playout.
+func newMCTSNode(b *mnkBoard, parent *mctsNode, square int) *mctsNode {
+	n := &mctsNode{parent: parent, square: square, mover: -b.side, status: b.status}
+	if b.status != InProgress {
+		return n
+	}
+	squares := b.candidates()
+	if parent != nil {
+		if sq, ok := b.winningMove(squares); ok {
+			squares = []int{sq}
+		}
+	}
+	for _, sq := range squares {
+		n.untried = append(n.untried, int16(sq))
+	}
+	return n
+}
+
+// uct is the value of choosing n from its parent
+func (n *mctsNode) uct() float64 {
+	return n.wins/n.visits + mctsExploration*math.Sqrt(math.Log(n.parent.visits)/n.visits)
+}
+
+// MoveStat is what a search found out about a move
+type MoveStat struct {
+	Move Move `json:"move"`
+	// Playouts is how many playouts were made after the move
+	Playouts int `json:"playouts"`
+	// Score is the share of those playouts won by the side making the
+	// move, a draw counting as half, or 0 if it has no playouts
+	Score float64 `json:"score"`
+}
+
+// BestMove searches the position and returns the move tried most
+func (e *MCTSEngine) BestMove(ctx context.Context, bb Bitboard, side string) (Move, error) {
+	return e.BestPositionMove(ctx, PositionOf(bb, side))
+}
+
+// BestPositionMove is BestMove for a position of any variant
+func (e *MCTSEngine) BestPositionMove(ctx context.Context, p Position) (Move, error) {
+	e.searchMu.Lock()
+	defer e.searchMu.Unlock()
+	stats, err := e.analyze(ctx, p)
+	if err != nil {
+		return Move{}, err
+	}
+	e.mu.Lock()
+	e.keep(stats[0].Move)
+	e.mu.Unlock()
+	return stats[0].Move, nil
+}
+
+// Analyze searches the position and returns what it found out about each
+// move, the most tried first. Moves not tried before the search stopped
+// come last with no playouts.
+func (e *MCTSEngine) Analyze(ctx context.Context, bb Bitboard, side string) ([]MoveStat, error) {
+	return e.AnalyzePosition(ctx, PositionOf(bb, side))
+}
+
+// AnalyzePosition is Analyze for a position of any variant
+func (e *MCTSEngine) AnalyzePosition(ctx context.Context, p Position) ([]MoveStat, error) {
+	e.searchMu.Lock()
+	defer e.searchMu.Unlock()
+	return e.analyze(ctx, p)
+}
+
+// analyze is AnalyzePosition for callers holding searchMu
+func (e *MCTSEngine) analyze(ctx context.Context, p Position) ([]MoveStat, error) {
+	b, err := p.board()
+	if err != nil {
+		return nil, err
+	}
+	if e.MoveTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.MoveTime)
+		defer cancel()
+	}
+	iterations := e.Iterations
+	if iterations <= 0 && e.MoveTime <= 0 {
+		iterations = defaultMCTSIterations
+	}
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	e.mu.Lock()
+	e.reuse(b)
+	seed := e.seed
+	e.seed += int64(workers)
+	e.mu.Unlock()
+
+	// each worker takes a playout at a time until the budget is spent
+	var (
+		wg      sync.WaitGroup
+		spentMu sync.Mutex
+		spent   int
+	)
+	next := func() bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		spentMu.Lock()
+		defer spentMu.Unlock()
+		if iterations > 0 && spent >= iterations {
+			return false
+		}
+		spent++
+		return true
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(r *rand.Rand) {
+			defer wg.Done()
+			scratch := &mnkBoard{}
+			for next() {
+				e.iterate(r, scratch)
+			}
+		}(rand.New(rand.NewSource(seed + int64(i))))
+	}
+	wg.Wait()
+	if spent == 0 {
+		// out of time before a single playout, one is needed for a move
+		e.iterate(rand.New(rand.NewSource(seed)), &mnkBoard{})
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := make([]MoveStat, 0, len(e.root.children)+len(e.root.untried))
+	for _, c := range e.root.children {
+		stats = append(stats, MoveStat{
+			Move:     e.board.move(c.square),
+			Playouts: int(c.visits),
+			Score:    c.wins / c.visits,
+		})
+	}
+	// moves the budget ran out before trying are still moves
+	for _, sq := range e.root.untried {
+		stats = append(stats, MoveStat{Move: e.board.move(int(sq))})
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].Playouts > stats[j].Playouts })
+	return stats, nil
+}
+
+// reuse makes the root the node for b, keeping it from the last search if
+// it is in the tree. The caller holds mu.
+func (e *MCTSEngine) reuse(b *mnkBoard) {
+	if n := e.find(b); n != nil {
+		n.parent = nil
+		e.root = n
+	} else {
+		e.root = newMCTSNode(b, nil, -1)
+	}
+	e.board = b
+}
+
+// find returns the node for b if it is the root's position or one or two
+// moves on from it, which is where the next search usually is
+func (e *MCTSEngine) find(b *mnkBoard) *mctsNode {
+	if e.root == nil || e.board.v != b.v {
+		return nil
+	}
+	// the squares played since, the root's side to move first
+	var played []int
+	for sq, piece := range e.board.cells {
+		switch {
+		case piece == b.cells[sq]:
+		case piece != blank:
+			return nil
+		case b.cells[sq] == e.board.side:
+			played = append([]int{sq}, played...)
+		default:
+			played = append(played, sq)
+		}
+	}
+	if len(played) > 2 || (len(played)%2 == 0) != (b.side == e.board.side) {
+		return nil
+	}
+
+	n := e.root
+	for i, sq := range played {
+		if (i%2 == 0) != (b.cells[sq] == e.board.side) {
+			return nil
+		}
+		var child *mctsNode
+		for _, c := range n.children {
+			if c.square == sq {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			return nil
+		}
+		n = child
+	}
+	return n
+}
+
+// keep makes the position after m the root, so the next search starts
+// from what is known about it. The caller holds mu.
+func (e *MCTSEngine) keep(m Move) {
+	square := m.YAxis*e.board.v.Width + m.XAxis
+	for _, c := range e.root.children {
+		if c.square == square {
+			b := &mnkBoard{}
+			b.copyFrom(e.board)
+			b.play(square)
+			c.parent = nil
+			e.root, e.board = c, b
+			return
+		}
+	}
+}
+
+// iterate makes one playout: it walks down the tree choosing children by
+// UCT, adds a child for an untried move, plays randomly from there to the
+// end of the game and counts the result in every node on the way. b is
+// the worker's board to play on.
+func (e *MCTSEngine) iterate(r *rand.Rand, b *mnkBoard) {
+	e.mu.Lock()
+	b.copyFrom(e.board)
+	n := e.root
+	n.visits++
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		best := n.children[0]
+		for _, c := range n.children[1:] {
+			if c.uct() > best.uct() {
+				best = c
+			}
+		}
+		n = best
+		b.play(n.square)
+		n.visits++
+	}
+	if len(n.untried) > 0 {
+		i := r.Intn(len(n.untried))
+		square := int(n.untried[i])
+		n.untried[i] = n.untried[len(n.untried)-1]
+		n.untried = n.untried[:len(n.untried)-1]
+		b.play(square)
+		child := newMCTSNode(b, n, square)
+		n.children = append(n.children, child)
+		n = child
+		n.visits++
+	}
+	e.mu.Unlock()
+
+	// the playout is the slow part, so it is made without the lock
+	result := playout(r, b)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ; n != nil; n = n.parent {
+		switch result {
+		case Cats:
+			n.wins += 0.5
+		case winner(n.mover):
+			n.wins++
+		}
+	}
+}
+
+// playout plays random moves on b until the game ends, returning the
+// result
+func playout(r *rand.Rand, b *mnkBoard) Status {
+	if b.status != InProgress {
+		return b.status
+	}
+	empty := b.empty()
+	for b.status == InProgress {
+		i := r.Intn(len(empty))
+		square := empty[i]
+		empty[i] = empty[len(empty)-1]
+		empty = empty[:len(empty)-1]
+		b.play(square)
+	}
+	return b.status
+}
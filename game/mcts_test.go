@@ -0,0 +1,190 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMCTSEngine(t *testing.T) {
+	tCases := []struct {
+		name     string
+		board    Bitboard
+		side     string
+		expected Move
+		err      error
+	}{
+		{
+			name:     "takes the win",
+			board:    bitboardOf("xx.", "oo.", "..."),
+			side:     "X",
+			expected: Move{XAxis: 2, YAxis: 0},
+		},
+		{
+			name:     "takes the win as O",
+			board:    bitboardOf("xx.", "oo.", "x.."),
+			side:     "O",
+			expected: Move{XAxis: 2, YAxis: 1},
+		},
+		{
+			name:     "blocks",
+			board:    bitboardOf("x..", ".x.", "o.."),
+			side:     "O",
+			expected: Move{XAxis: 2, YAxis: 2},
+		},
+		{
+			name:  "no moves once the game is won",
+			board: bitboardOf("xxx", "oo.", "..."),
+			side:  "O",
+			err:   ErrNoMoves,
+		},
+		{
+			name:  "needs a side",
+			board: bitboardOf("...", "...", "..."),
+			side:  "Y",
+			err:   ErrInvalidSide,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewMCTSEngine(1)
+			e.Iterations = 2000
+			move, err := e.BestMove(context.Background(), tc.board, tc.side)
+			assert.Equal(t, tc.err, err)
+			if err == nil {
+				assert.Equal(t, tc.expected, move)
+			}
+		})
+	}
+}
+
+func TestMCTSNeverLoses(t *testing.T) {
+	mcts, random := NewMCTSEngine(1), NewRandomEngine(1)
+	mcts.Iterations, mcts.Workers = 2000, 4
+	for i := 0; i < 20; i++ {
+		assert.NotEqual(t, OWins, playEngines(t, mcts, random))
+		assert.NotEqual(t, XWins, playEngines(t, random, mcts))
+	}
+	assert.Equal(t, Cats, playEngines(t, mcts, NewMinimaxEngine()))
+}
+
+func TestMCTSAnalyze(t *testing.T) {
+	e := NewMCTSEngine(1)
+	e.Iterations = 1000
+	stats, err := e.Analyze(context.Background(), bitboardOf("xx.", "oo.", "..."), "X")
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 5) {
+		assert.Equal(t, Move{XAxis: 2, YAxis: 0}, stats[0].Move)
+		assert.Equal(t, 1.0, stats[0].Score)
+	}
+	total := 0
+	for _, s := range stats {
+		total += s.Playouts
+	}
+	assert.Equal(t, 1000, total)
+}
+
+func TestMCTSReusesTree(t *testing.T) {
+	e := NewMCTSEngine(1)
+	e.Iterations = 1000
+	bb := bitboardOf("...", "...", "...")
+	move, err := e.BestMove(context.Background(), bb, "X")
+	assert.NoError(t, err)
+	bb, _ = bb.Play("X", move)
+	reply, err := FirstOpenEngine{}.BestMove(context.Background(), bb, "O")
+	assert.NoError(t, err)
+	bb, _ = bb.Play("O", reply)
+
+	// a single playout, on top of the ones under the position already
+	e.Iterations = 1
+	stats, err := e.Analyze(context.Background(), bb, "X")
+	assert.NoError(t, err)
+	total := 0
+	for _, s := range stats {
+		total += s.Playouts
+	}
+	assert.True(t, total > 1, "expected playouts from the last move, got %d", total)
+}
+
+func TestMCTSMoveTime(t *testing.T) {
+	e := NewMCTSEngine(1)
+	e.MoveTime = 20 * time.Millisecond
+	start := time.Now()
+	_, err := e.BestMove(context.Background(), bitboardOf("...", "...", "..."), "X")
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// a move is made even with no time at all
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewMCTSEngine(1).BestMove(ctx, bitboardOf("...", "...", "..."), "X")
+	assert.NoError(t, err)
+
+	// and every move is analyzed, the ones not tried with no playouts
+	stats, err := NewMCTSEngine(1).Analyze(ctx, bitboardOf("...", "...", "..."), "X")
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 9) {
+		assert.Equal(t, 1, stats[0].Playouts)
+		assert.Equal(t, 0, stats[8].Playouts)
+	}
+}
+
+func TestMCTSLargeBoard(t *testing.T) {
+	gomoku := Variant{Width: 15, Height: 15, K: 5}
+	tCases := []struct {
+		name     string
+		position Position
+		expected Move
+	}{
+		{
+			name:     "takes the win",
+			position: positionOf(gomoku, "X", "", "", "", "", "", "", "", "...oxxxx.", "....ooo"),
+			expected: Move{XAxis: 8, YAxis: 7},
+		},
+		{
+			name:     "blocks",
+			position: positionOf(gomoku, "O", "", "", "", "", "", "", "", "", "...oxxxx.", "....o.o"),
+			expected: Move{XAxis: 8, YAxis: 8},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewMCTSEngine(1)
+			e.Iterations = 5000
+			move, err := e.BestPositionMove(context.Background(), tc.position)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, move)
+		})
+	}
+}
+
+func TestMCTSAnalyzePosition(t *testing.T) {
+	e := NewMCTSEngine(1)
+	e.Iterations = 500
+	p := NewPosition(Variant{Width: 7, Height: 6, K: 4})
+	stats, err := e.AnalyzePosition(context.Background(), p)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 42)
+	for _, s := range stats {
+		assert.True(t, s.Move.XAxis < 7 && s.Move.YAxis < 6, "expected moves on the board, got %+v", s.Move)
+	}
+
+	// the tree is kept for a reply on a board of the same variant
+	p.Board[stats[0].Move.YAxis][stats[0].Move.XAxis] = xPiece
+	p.Side = "O"
+	e.Iterations = 1
+	stats, err = e.AnalyzePosition(context.Background(), p)
+	assert.NoError(t, err)
+	total := 0
+	for _, s := range stats {
+		total += s.Playouts
+	}
+	assert.True(t, total > 1, "expected playouts from the last search, got %d", total)
+
+	_, err = e.AnalyzePosition(context.Background(), NewPosition(Variant{Width: 30, Height: 3, K: 3}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package game
+
+import "time"
+
+// Metrics is told about game events, for monitoring. Calls are made while
+// the game is being changed, so they must be quick and must not call back
+// into the game.
+type Metrics interface {
+	// GameStarted is called when both players are in and the board is
+	// clear
+	GameStarted()
+	// GameFinished is called with XWins, OWins or Cats
+	GameFinished(result Status)
+	// MovePlaced is called for every move, with how long the player took
+	// since their turn started. auto is set for moves made on timeout.
+	MovePlaced(took time.Duration, auto bool)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) GameStarted()                   {}
+func (nopMetrics) GameFinished(Status)            {}
+func (nopMetrics) MovePlaced(time.Duration, bool) {}
+
+// SetMetrics sets what the game reports events to
+func (g *Game) SetMetrics(m Metrics) {
+	if m == nil {
+		m = nopMetrics{}
+	}
+	g.lock()
+	defer g.unlock()
+	g.metrics = m
+}
+
+// observe returns the game's metrics, which report nowhere for games
+// made without New
+func (g *Game) observe() Metrics {
+	if g.metrics == nil {
+		return nopMetrics{}
+	}
+	return g.metrics
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMetrics remembers the events it is told about
+type recordingMetrics struct {
+	sync.Mutex
+	started  int
+	finished []Status
+	auto     []bool
+}
+
+func (m *recordingMetrics) GameStarted() {
+	m.Lock()
+	defer m.Unlock()
+	m.started++
+}
+
+func (m *recordingMetrics) GameFinished(result Status) {
+	m.Lock()
+	defer m.Unlock()
+	m.finished = append(m.finished, result)
+}
+
+func (m *recordingMetrics) MovePlaced(_ time.Duration, auto bool) {
+	m.Lock()
+	defer m.Unlock()
+	m.auto = append(m.auto, auto)
+}
+
+func (m *recordingMetrics) moves() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.auto)
+}
+
+func TestMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	g := New(logrus.WithField("test", true), 20*time.Millisecond, nil)
+	g.SetMetrics(m)
+
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.PlacePiece(Move{PlayerID: "testIDX", XAxis: 1, YAxis: 1}))
+
+	// O times out
+	for start := time.Now(); m.moves() < 2 && time.Since(start) < time.Second; {
+		<-time.After(5 * time.Millisecond)
+	}
+	g.PauseTimeout()
+	assert.NoError(t, g.ForceResult(Cats))
+	g.Close()
+
+	m.Lock()
+	defer m.Unlock()
+	assert.Equal(t, 1, m.started)
+	assert.Equal(t, []Status{Cats}, m.finished)
+	if assert.True(t, len(m.auto) >= 2) {
+		assert.Equal(t, []bool{false, true}, m.auto[:2])
+	}
+}
@@ -0,0 +1,205 @@
+package game
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ModerationError is returned when a player or their name is rejected.
+// Code does not change between releases, so clients can match on it.
+type ModerationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ModerationError) Error() string { return e.Message }
+
+// Moderation errors
+var (
+	ErrPlayerBanned = &ModerationError{Code: "player_banned", Message: "player banned"}
+	ErrNameTooShort = &ModerationError{Code: "name_too_short", Message: "name is too short"}
+	ErrNameTooLong  = &ModerationError{Code: "name_too_long", Message: "name is too long"}
+	ErrNameCharset  = &ModerationError{Code: "name_charset", Message: "name has characters that are not allowed"}
+	ErrNameBlocked  = &ModerationError{Code: "name_blocked", Message: "name has a blocked word"}
+
+	ErrInvalidNameRules = errors.New("invalid name rules")
+)
+
+// NameRules limit what players can call themselves. Names are optional,
+// the rules only apply to players who set one.
+type NameRules struct {
+	// MinLength and MaxLength count characters, ignoring leading and
+	// trailing spaces. A MaxLength of 0 is no limit.
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	// Charset is a regular expression the whole name must match
+	Charset string `json:"charset"`
+	// BlockedWords are rejected when they appear as a word in a name,
+	// ignoring case
+	BlockedWords []string `json:"blocked_words"`
+}
+
+// DefaultNameRules allow letters, numbers, spaces and a little
+// punctuation, up to 24 characters
+var DefaultNameRules = NameRules{
+	MinLength:    1,
+	MaxLength:    24,
+	Charset:      `^[\p{L}\p{N} _.'-]*$`,
+	BlockedWords: []string{},
+}
+
+// moderation holds the ban list and compiled name rules. It is guarded
+// by the game's lock.
+type moderation struct {
+	banned  map[string]struct{}
+	rules   NameRules
+	charset *regexp.Regexp
+	blocked map[string]struct{}
+}
+
+func newModeration() *moderation {
+	m := &moderation{banned: map[string]struct{}{}}
+	if err := m.setRules(DefaultNameRules); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m *moderation) setRules(r NameRules) error {
+	if r.MinLength < 0 || r.MaxLength < 0 || (r.MaxLength > 0 && r.MaxLength < r.MinLength) {
+		return ErrInvalidNameRules
+	}
+	charset, err := regexp.Compile(r.Charset)
+	if err != nil {
+		return err
+	}
+	blocked := map[string]struct{}{}
+	words := []string{}
+	for _, w := range r.BlockedWords {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if _, ok := blocked[w]; ok || w == "" {
+			continue
+		}
+		blocked[w] = struct{}{}
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	r.BlockedWords = words
+
+	m.rules, m.charset, m.blocked = r, charset, blocked
+	return nil
+}
+
+func (m *moderation) checkName(name string) error {
+	name = strings.TrimSpace(name)
+	n := utf8.RuneCountInString(name)
+	if n < m.rules.MinLength {
+		return ErrNameTooShort
+	}
+	if m.rules.MaxLength > 0 && n > m.rules.MaxLength {
+		return ErrNameTooLong
+	}
+	if !m.charset.MatchString(name) {
+		return ErrNameCharset
+	}
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, w := range words {
+		if _, ok := m.blocked[w]; ok {
+			return ErrNameBlocked
+		}
+	}
+	return nil
+}
+
+// mod returns the game's moderation, creating it for games that were not
+// made with New
+func (g *Game) mod() *moderation {
+	if g.moderation == nil {
+		g.moderation = newModeration()
+	}
+	return g.moderation
+}
+
+// checkPlayer returns a ModerationError if p may not play
+func (g *Game) checkPlayer(p Player) error {
+	if _, ok := g.mod().banned[p.ID]; ok {
+		return ErrPlayerBanned
+	}
+	if p.Name != nil {
+		return g.mod().checkName(*p.Name)
+	}
+	return nil
+}
+
+// Ban removes a player, if they are playing or queued, and stops them
+// from subscribing again
+func (g *Game) Ban(id string) error {
+	g.lock()
+	defer g.unlock()
+	g.log.WithField("player_id", id).Warn("banning player")
+	g.mod().banned[id] = struct{}{}
+	if err := g.removePlayer(id); err != nil && err != ErrPlayerNotFound {
+		return err
+	}
+	return nil
+}
+
+// Unban lets a banned player subscribe again
+func (g *Game) Unban(id string) error {
+	g.lock()
+	defer g.unlock()
+	if _, ok := g.mod().banned[id]; !ok {
+		return ErrPlayerNotFound
+	}
+	g.log.WithField("player_id", id).Warn("unbanning player")
+	delete(g.mod().banned, id)
+	return nil
+}
+
+// Banned reports whether the player with id is banned
+func (g *Game) Banned(id string) bool {
+	g.lock()
+	defer g.unlock()
+	_, ok := g.mod().banned[id]
+	return ok
+}
+
+// Bans lists the ids of banned players
+func (g *Game) Bans() []string {
+	g.lock()
+	defer g.unlock()
+	ids := []string{}
+	for id := range g.mod().banned {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// NameRules returns the rules for player names
+func (g *Game) NameRules() NameRules {
+	g.lock()
+	defer g.unlock()
+	r := g.mod().rules
+	r.BlockedWords = append([]string{}, r.BlockedWords...)
+	return r
+}
+
+// SetNameRules replaces the rules for player names. Players who already
+// have a name keep it.
+func (g *Game) SetNameRules(r NameRules) error {
+	g.lock()
+	defer g.unlock()
+	if err := g.mod().setRules(r); err != nil {
+		g.log.WithError(err).Error("invalid name rules")
+		return err
+	}
+	g.log.WithField("rules", g.mod().rules).Info("name rules updated")
+	return nil
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckName(t *testing.T) {
+	rules := DefaultNameRules
+	rules.BlockedWords = []string{"Jerk", "  ", "jerk"}
+
+	tCases := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{name: "allows letters and numbers", input: "nat 2", expected: nil},
+		{name: "allows letters from any language", input: "Zoë", expected: nil},
+		{name: "rejects blank names", input: "   ", expected: ErrNameTooShort},
+		{name: "rejects long names", input: "abcdefghijklmnopqrstuvwxy", expected: ErrNameTooLong},
+		{name: "counts characters, not bytes", input: "ééééééééééééééééééééé", expected: nil},
+		{name: "rejects markup", input: "<b>nat</b>", expected: ErrNameCharset},
+		{name: "rejects blocked words ignoring case", input: "big JERK", expected: ErrNameBlocked},
+		{name: "rejects blocked words between punctuation", input: "the_jerk", expected: ErrNameBlocked},
+		{name: "allows blocked words inside other words", input: "jerky", expected: nil},
+	}
+
+	m := newModeration()
+	assert.NoError(t, m.setRules(rules))
+	assert.Equal(t, []string{"jerk"}, m.rules.BlockedWords)
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, m.checkName(tc.input))
+		})
+	}
+}
+
+func TestSetNameRules(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.Equal(t, ErrInvalidNameRules, g.SetNameRules(NameRules{MinLength: 5, MaxLength: 2}))
+	assert.Error(t, g.SetNameRules(NameRules{Charset: "["}))
+	assert.Equal(t, DefaultNameRules, g.NameRules(), "invalid rules are not applied")
+
+	assert.NoError(t, g.SetNameRules(NameRules{MaxLength: 3, Charset: "^[a-z]*$"}))
+	name := "four"
+	assert.Equal(t, ErrNameTooLong, g.AddPlayer(Player{ID: "x", Name: &name}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "x"}), "names are optional")
+
+	name = "Nat"
+	assert.Equal(t, ErrNameCharset, g.UpdatePlayer(Player{ID: "x", Name: &name}))
+	assert.Nil(t, g.X.Name)
+}
+
+func TestUnban(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	assert.Equal(t, ErrPlayerNotFound, g.Unban("x"))
+
+	assert.NoError(t, g.Ban("b"))
+	assert.NoError(t, g.Ban("a"))
+	assert.Equal(t, []string{"a", "b"}, g.Bans())
+
+	assert.NoError(t, g.Unban("a"))
+	assert.Equal(t, []string{"b"}, g.Bans())
+	assert.NoError(t, g.AddPlayer(Player{ID: "a"}))
+}
+
+func TestModerationConcurrent(t *testing.T) {
+	g := New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	name := "nat"
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(id string) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				assert.NoError(t, g.Ban(id))
+				assert.NoError(t, g.SetNameRules(DefaultNameRules))
+				assert.NoError(t, g.Unban(id))
+			}
+		}(fmt.Sprint("banned", i))
+		go func(id string) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				assert.NoError(t, g.AddPlayer(Player{ID: id, Name: &name}))
+				assert.NoError(t, g.RemovePlayer(id))
+				g.Banned(id)
+				g.Bans()
+				g.NameRules()
+			}
+		}(fmt.Sprint("player", i))
+	}
+	wg.Wait()
+	assert.Empty(t, g.Bans())
+}
@@ -0,0 +1,261 @@
+package game
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notation errors
+var (
+	ErrInvalidNotation = errors.New("invalid game notation")
+	ErrResultMismatch  = errors.New("result does not match moves")
+)
+
+// Notation is a PGN like text format for game records. Headers come
+// first, one per line, followed by the numbered moves and the result:
+//
+//	[X "id"]
+//	[XName "name"]
+//	[O "id"]
+//	[Date "2018.07.19"]
+//	[Result "1-0"]
+//	[Variant "standard"]
+//	[TimeControl "5s"]
+//	[First "X"]
+//
+//	1. a1 a2 2. b1 b2 3. c1 1-0
+//
+// Squares are written as a column letter (a-c for x_axis 0-2) followed by
+// a row number (1-3 for y_axis 0-2). Results are 1-0 for X, 0-1 for O,
+// 1/2-1/2 for cats and * for unfinished games.
+const (
+	resultXWins      = "1-0"
+	resultOWins      = "0-1"
+	resultCats       = "1/2-1/2"
+	resultInProgress = "*"
+
+	standardVariant = "standard"
+	dateLayout      = "2006.01.02"
+)
+
+var (
+	headerRe = regexp.MustCompile(`^\[(\w+)\s+"((?:[^"\\]|\\.)*)"\]$`)
+	squareRe = regexp.MustCompile(`^([a-c])([1-3])$`)
+	moveNoRe = regexp.MustCompile(`^\d+\.$`)
+)
+
+func resultToken(s Status) string {
+	switch s {
+	case XWins:
+		return resultXWins
+	case OWins:
+		return resultOWins
+	case Cats:
+		return resultCats
+	}
+	return resultInProgress
+}
+
+func tokenResult(tok string) (Status, bool) {
+	switch tok {
+	case resultXWins:
+		return XWins, true
+	case resultOWins:
+		return OWins, true
+	case resultCats:
+		return Cats, true
+	case resultInProgress:
+		return InProgress, true
+	}
+	return "", false
+}
+
+// Square returns the algebraic name of the square a move was placed on
+func (m Move) Square() string {
+	return fmt.Sprintf("%c%d", 'a'+m.XAxis, m.YAxis+1)
+}
+
+// ParseSquare returns the x and y axis of an algebraic square like b2
+func ParseSquare(s string) (x, y int, err error) {
+	match := squareRe.FindStringSubmatch(strings.ToLower(s))
+	if match == nil {
+		return -1, -1, ErrInvalidMove
+	}
+	return int(match[1][0] - 'a'), int(match[2][0] - '1'), nil
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// WriteNotation writes r to w in notation format
+func WriteNotation(w io.Writer, r *Record) error {
+	first := "X"
+	if len(r.Moves) > 0 && r.Moves[0].PlayerID == r.O.ID {
+		first = "O"
+	}
+	variant := r.Variant
+	if variant == "" {
+		variant = standardVariant
+	}
+
+	var b strings.Builder
+	header := func(k, v string) { fmt.Fprintf(&b, "[%s %s]\n", k, quote(v)) }
+	header("X", r.X.ID)
+	if r.X.Name != nil {
+		header("XName", *r.X.Name)
+	}
+	header("O", r.O.ID)
+	if r.O.Name != nil {
+		header("OName", *r.O.Name)
+	}
+	if !r.Date.IsZero() {
+		header("Date", r.Date.Format(dateLayout))
+	}
+	header("Result", resultToken(r.Result))
+	header("Variant", variant)
+	if r.TimeControl > 0 {
+		header("TimeControl", r.TimeControl.String())
+	}
+	header("First", first)
+	b.WriteString("\n")
+
+	for i, m := range r.Moves {
+		if i%2 == 0 {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "%d. ", i/2+1)
+		} else {
+			b.WriteString(" ")
+		}
+		b.WriteString(m.Square())
+	}
+	if len(r.Moves) > 0 {
+		b.WriteString(" ")
+	}
+	b.WriteString(resultToken(r.Result))
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ParseNotation reads a record in notation format from rd. The moves are
+// replayed to make sure they are legal and agree with the Result header.
+func ParseNotation(rd io.Reader) (*Record, error) {
+	r := &Record{
+		X:       Player{ID: "X"},
+		O:       Player{ID: "O"},
+		Variant: standardVariant,
+	}
+	first := "X"
+	result := ""
+
+	var squares []string
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			match := headerRe.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("%v: bad header %q", ErrInvalidNotation, line)
+			}
+			v, err := strconv.Unquote(`"` + match[2] + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("%v: bad header %q", ErrInvalidNotation, line)
+			}
+			switch match[1] {
+			case "X":
+				r.X.ID = v
+			case "XName":
+				r.X.Name = &v
+			case "O":
+				r.O.ID = v
+			case "OName":
+				r.O.Name = &v
+			case "Date":
+				if r.Date, err = time.Parse(dateLayout, v); err != nil {
+					return nil, fmt.Errorf("%v: bad date %q", ErrInvalidNotation, v)
+				}
+			case "Result":
+				result = v
+			case "Variant":
+				r.Variant = v
+			case "TimeControl":
+				if r.TimeControl, err = time.ParseDuration(v); err != nil {
+					return nil, fmt.Errorf("%v: bad time control %q", ErrInvalidNotation, v)
+				}
+			case "First":
+				if v != "X" && v != "O" {
+					return nil, fmt.Errorf("%v: bad first player %q", ErrInvalidNotation, v)
+				}
+				first = v
+			}
+			continue
+		}
+
+		for _, tok := range strings.Fields(line) {
+			if moveNoRe.MatchString(tok) {
+				continue
+			}
+			if _, ok := tokenResult(tok); ok {
+				if result != "" && result != tok {
+					return nil, ErrResultMismatch
+				}
+				result = tok
+				continue
+			}
+			squares = append(squares, tok)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if r.Variant != standardVariant {
+		return nil, fmt.Errorf("%v: unsupported variant %q", ErrInvalidNotation, r.Variant)
+	}
+	if r.X.ID == r.O.ID {
+		return nil, fmt.Errorf("%v: players must differ", ErrInvalidNotation)
+	}
+
+	ids := [2]string{r.X.ID, r.O.ID}
+	if first == "O" {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+
+	var bb Bitboard
+	for i, sq := range squares {
+		if bb.Status() != InProgress {
+			return nil, fmt.Errorf("%v: move %q after game over", ErrInvalidNotation, sq)
+		}
+		x, y, err := ParseSquare(sq)
+		if err != nil {
+			return nil, fmt.Errorf("%v: bad square %q", ErrInvalidNotation, sq)
+		}
+		if bb.At(x, y) != blank {
+			return nil, fmt.Errorf("%v: square %q already used", ErrInvalidNotation, sq)
+		}
+		m := Move{PlayerID: ids[i%2], XAxis: x, YAxis: y}
+		p, _ := r.piece(m.PlayerID)
+		bb = bb.Place(p, x, y)
+		r.Moves = append(r.Moves, m)
+	}
+
+	r.Result = bb.Status()
+	if result != "" && result != resultToken(r.Result) {
+		return nil, ErrResultMismatch
+	}
+	return r, nil
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotationRoundTrip(t *testing.T) {
+	name := `Nat "the cat"`
+	rec := &Record{
+		X: Player{ID: "testIDX", Name: &name},
+		O: Player{ID: "testIDO"},
+		Moves: []Move{
+			{PlayerID: "testIDO", XAxis: 1, YAxis: 1},
+			{PlayerID: "testIDX", XAxis: 0, YAxis: 0},
+			{PlayerID: "testIDO", XAxis: 2, YAxis: 0},
+			{PlayerID: "testIDX", XAxis: 0, YAxis: 1},
+			{PlayerID: "testIDO", XAxis: 0, YAxis: 2},
+		},
+		Result:      OWins,
+		Date:        time.Date(2018, 7, 19, 0, 0, 0, 0, time.UTC),
+		Variant:     standardVariant,
+		TimeControl: 5 * time.Second,
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteNotation(&buf, rec))
+	assert.Equal(t, `[X "testIDX"]
+[XName "Nat \"the cat\""]
+[O "testIDO"]
+[Date "2018.07.19"]
+[Result "0-1"]
+[Variant "standard"]
+[TimeControl "5s"]
+[First "O"]
+
+1. b2 a1 2. c1 a2 3. a3 0-1
+`, buf.String())
+
+	parsed, err := ParseNotation(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, rec, parsed)
+}
+
+func TestParseNotation(t *testing.T) {
+	tCases := []struct {
+		name     string
+		input    string
+		err      error
+		expected Status
+	}{
+		{
+			name:     "Defaults players and infers the result",
+			input:    "1. a1 a2 2. b1 b2 3. c1",
+			expected: XWins,
+		},
+		{
+			name:     "Accepts unfinished games",
+			input:    "[Result \"*\"]\n1. a1 a2 *",
+			expected: InProgress,
+		},
+		{
+			name:  "Rejects a result that does not match the moves",
+			input: "1. a1 a2 2. b1 b2 3. c1 0-1",
+			err:   ErrResultMismatch,
+		},
+		{
+			name:  "Rejects reused squares",
+			input: "1. a1 a1",
+			err:   ErrInvalidNotation,
+		},
+		{
+			name:  "Rejects moves after the game is over",
+			input: "1. a1 a2 2. b1 b2 3. c1 c2",
+			err:   ErrInvalidNotation,
+		},
+		{
+			name:  "Rejects squares off the board",
+			input: "1. d4",
+			err:   ErrInvalidNotation,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, err := ParseNotation(strings.NewReader(tc.input))
+			if tc.err != nil {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, rec.Result)
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Process engine errors
+var (
+	ErrEngineExited    = errors.New("engine exited")
+	ErrEngineReply     = errors.New("engine sent an invalid reply")
+	ErrEngineHandshake = errors.New("engine did not answer ttt with tttok")
+)
+
+const (
+	// processQuitTimeout is how long an engine is given to exit after
+	// quit before it is killed
+	processQuitTimeout = time.Second
+	// processMoveMargin is taken off the time left when telling an engine
+	// how long it has, to leave time for its answer to arrive
+	processMoveMargin = 20 * time.Millisecond
+)
+
+// ProcessEngine runs an engine in another process, so engines can be
+// written in any language. It speaks a line based protocol over the
+// process's stdin and stdout, much like UCI in chess. When started the
+// engine is sent ttt, and answers with its name and tttok:
+//
+//	ttt
+//	id name first-open
+//	tttok
+//
+// For each move it is sent the board and the side to move, then go with
+// the milliseconds it has to answer, and answers with the square to play:
+//
+//	position x...o.... x
+//	go movetime 980
+//	bestmove c1
+//
+// Boards are 9 characters, x, o or . for an empty square, from a1 to c1
+// then a2 to c3. go is sent without movetime when there is no time
+// limit. An engine still thinking when its time is up is sent stop, and
+// should answer with its best move so far straight away.
+//
+// Before the next move an engine that answered late is sent isready and
+// everything up to its readyok is thrown away. newgame is sent before
+// the first move of each game and quit when the engine is closed. Lines
+// from the engine that aren't expected, like info lines, are ignored.
+type ProcessEngine struct {
+	// mu makes sure only one move is asked for at a time
+	mu    sync.Mutex
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+	name  string
+	// stale is set when the engine may still send an answer that was not
+	// waited for
+	stale bool
+}
+
+// StartProcessEngine runs name with args as an engine. ctx bounds how
+// long the engine has to answer ttt.
+func StartProcessEngine(ctx context.Context, name string, args ...string) (*ProcessEngine, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &ProcessEngine{cmd: cmd, stdin: stdin, lines: make(chan string), name: filepath.Base(name)}
+	go func() {
+		defer close(p.lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			p.lines <- scanner.Text()
+		}
+	}()
+
+	if err := p.handshake(ctx); err != nil {
+		p.cmd.Process.Kill()
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// handshake sends ttt and waits for tttok, reading the engine's name
+func (p *ProcessEngine) handshake(ctx context.Context) error {
+	if err := p.send("ttt"); err != nil {
+		return err
+	}
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return ErrEngineHandshake
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 1 && fields[0] == "tttok":
+				return nil
+			case len(fields) > 2 && fields[0] == "id" && fields[1] == "name":
+				p.name = strings.Join(fields[2:], " ")
+			}
+		case <-ctx.Done():
+			return ErrEngineHandshake
+		}
+	}
+}
+
+// Name is the name the engine gave itself, or the name of its command
+func (p *ProcessEngine) Name() string {
+	return p.name
+}
+
+func (p *ProcessEngine) send(lines ...string) error {
+	_, err := io.WriteString(p.stdin, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// encodeBoard writes bb in the engine protocol's board format
+func encodeBoard(bb Bitboard) string {
+	var sb strings.Builder
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			switch bb.At(x, y) {
+			case xPiece:
+				sb.WriteByte('x')
+			case oPiece:
+				sb.WriteByte('o')
+			default:
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// goCommand is the go line for a move that must be made by the deadline
+// of ctx
+func goCommand(ctx context.Context) string {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "go"
+	}
+	ms := (time.Until(deadline) - processMoveMargin).Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return fmt.Sprintf("go movetime %d", ms)
+}
+
+// BestMove asks the engine for its move. When ctx is done before the
+// engine answers it is sent stop, and its answer is thrown away before
+// the next move is asked for.
+func (p *ProcessEngine) BestMove(ctx context.Context, bb Bitboard, side string) (Move, error) {
+	if _, err := openSquares(bb, side); err != nil {
+		return Move{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.sync(ctx); err != nil {
+		return Move{}, err
+	}
+
+	position := fmt.Sprintf("position %s %s", encodeBoard(bb), strings.ToLower(side))
+	if err := p.send(position, goCommand(ctx)); err != nil {
+		return Move{}, err
+	}
+
+	fields, err := p.waitFor(ctx, "bestmove")
+	if err != nil {
+		if err == ctx.Err() {
+			p.stale = true
+			p.send("stop")
+		}
+		return Move{}, err
+	}
+	if len(fields) != 2 {
+		return Move{}, ErrEngineReply
+	}
+	x, y, err := ParseSquare(fields[1])
+	if err != nil {
+		return Move{}, ErrEngineReply
+	}
+	return Move{XAxis: x, YAxis: y}, nil
+}
+
+// sync waits for an engine that answered late to catch up, so the next
+// bestmove read is for the move being asked for. The caller holds mu.
+func (p *ProcessEngine) sync(ctx context.Context) error {
+	if !p.stale {
+		return nil
+	}
+	if err := p.send("isready"); err != nil {
+		return err
+	}
+	if _, err := p.waitFor(ctx, "readyok"); err != nil {
+		return err
+	}
+	p.stale = false
+	return nil
+}
+
+// waitFor reads lines from the engine until one starting with token, and
+// returns its fields. The caller holds mu.
+func (p *ProcessEngine) waitFor(ctx context.Context, token string) ([]string, error) {
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return nil, ErrEngineExited
+			}
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == token {
+				return fields, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// NewGame tells the engine the next move is the first of a new game, so
+// it can forget the last one
+func (p *ProcessEngine) NewGame() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.send("newgame")
+}
+
+// Close asks the engine to quit, killing it if it doesn't
+func (p *ProcessEngine) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.send("quit")
+	p.stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() {
+		// the process can't exit while its output is unread
+		for range p.lines {
+		}
+		exited <- p.cmd.Wait()
+	}()
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(processQuitTimeout):
+		p.cmd.Process.Kill()
+		return <-exited
+	}
+}
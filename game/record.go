@@ -0,0 +1,133 @@
+package game
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Record errors
+var (
+	ErrRecordNotFound = errors.New("game record not found")
+	ErrInvalidPly     = errors.New("invalid ply")
+)
+
+// maxRecords is how many finished games are kept in memory
+const maxRecords = 100
+
+// Record is a finished game, kept so it can be replayed
+type Record struct {
+	ID     string    `json:"id"`
+	X      Player    `json:"player_x"`
+	O      Player    `json:"player_o"`
+	Moves  []Move    `json:"moves"`
+	Result Status    `json:"result"`
+	Date   time.Time `json:"date"`
+
+	Variant     string        `json:"variant,omitempty"`
+	TimeControl time.Duration `json:"time_control,omitempty"`
+}
+
+// piece returns the piece placed by the player with id
+func (r *Record) piece(id string) (Piece, error) {
+	switch id {
+	case r.X.ID:
+		return xPiece, nil
+	case r.O.ID:
+		return oPiece, nil
+	}
+	return blank, ErrPlayerNotFound
+}
+
+// Position returns the board after ply moves have been played. Ply 0 is
+// the empty board.
+func (r *Record) Position(ply int) (*Board, error) {
+	if ply < 0 || ply > len(r.Moves) {
+		return nil, ErrInvalidPly
+	}
+	var b Board
+	for _, m := range r.Moves[:ply] {
+		p, err := r.piece(m.PlayerID)
+		if err != nil {
+			return nil, err
+		}
+		b[m.YAxis][m.XAxis] = p
+	}
+	return &b, nil
+}
+
+// Positions returns every board state of the game, starting with the
+// empty board and ending with the final position
+func (r *Record) Positions() ([]Board, error) {
+	positions := make([]Board, 0, len(r.Moves)+1)
+	var b Board
+	positions = append(positions, b)
+	for _, m := range r.Moves {
+		p, err := r.piece(m.PlayerID)
+		if err != nil {
+			return nil, err
+		}
+		b[m.YAxis][m.XAxis] = p
+		positions = append(positions, b)
+	}
+	return positions, nil
+}
+
+// record archives the finished game, returning its ID
+func (g *Game) record() string {
+	if g.X == nil || g.O == nil {
+		return ""
+	}
+	id := g.archive(Record{
+		X:           *g.X,
+		O:           *g.O,
+		Moves:       append([]Move(nil), g.History...),
+		Result:      g.Status,
+		Date:        time.Now().UTC(),
+		Variant:     standardVariant,
+		TimeControl: g.timeout,
+	})
+	g.log.WithField("record_id", id).Info("game recorded")
+	return id
+}
+
+// archive assigns r an ID and stores it, dropping the oldest record once
+// maxRecords is reached
+func (g *Game) archive(r Record) string {
+	g.recordSeq++
+	r.ID = strconv.Itoa(g.recordSeq)
+	g.records = append(g.records, r)
+	if len(g.records) > maxRecords {
+		g.records = g.records[len(g.records)-maxRecords:]
+	}
+	return r.ID
+}
+
+// Import adds r to the recorded games under a new ID, which is returned
+func (g *Game) Import(r Record) string {
+	g.lock()
+	defer g.unlock()
+	id := g.archive(r)
+	g.log.WithField("record_id", id).Info("game imported")
+	return id
+}
+
+// Records returns all recorded games, oldest first
+func (g *Game) Records() []Record {
+	g.lock()
+	defer g.unlock()
+	return append([]Record{}, g.records...)
+}
+
+// Record returns the recorded game with id, or ErrRecordNotFound
+func (g *Game) Record(id string) (*Record, error) {
+	g.lock()
+	defer g.unlock()
+	for i := range g.records {
+		if g.records[i].ID == id {
+			r := g.records[i]
+			return &r, nil
+		}
+	}
+	return nil, ErrRecordNotFound
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// turnTimer runs a single countdown at a time. Starting it again cancels
+// the running countdown, so a game never has more than one pending
+// timeout, and a cancelled countdown's goroutine returns straight away.
+type turnTimer struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// closed is set by close, after which start does nothing
+	closed bool
+}
+
+// start cancels any running countdown and calls fn after d, unless the
+// timer is stopped or started again first
+func (t *turnTimer) start(d time.Duration, fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	if t.cancel != nil {
+		t.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+
+		// the countdown may have been cancelled while the timer fired
+		t.mu.Lock()
+		current := ctx.Err() == nil
+		if current {
+			cancel()
+			t.cancel = nil
+		}
+		t.mu.Unlock()
+		if current {
+			// fn may start the timer again, so it runs without the lock
+			fn()
+		}
+	}()
+}
+
+// stop cancels the running countdown, if there is one
+func (t *turnTimer) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+}
+
+// close stops the timer for good and waits for a countdown that has
+// already fired to finish
+func (t *turnTimer) close() {
+	t.mu.Lock()
+	t.closed = true
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.mu.Unlock()
+	t.wait()
+}
+
+// running reports whether a countdown is pending
+func (t *turnTimer) running() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cancel != nil
+}
+
+// wait blocks until every countdown goroutine has returned. It must not
+// be called while the timer can still be started, except by close.
+func (t *turnTimer) wait() {
+	t.wg.Wait()
+}
+
+// turnTimer returns the game's timer, creating it for games that were
+// not made with New
+func (g *Game) turnTimer() *turnTimer {
+	if g.timer == nil {
+		g.timer = &turnTimer{}
+	}
+	return g.timer
+}
+
+// startTurn starts the clock for the player to move, timing them out
+// unless the timeout is paused
+func (g *Game) startTurn() {
+	g.turnStarted = time.Now()
+	if !g.Paused {
+		g.startTimeout()
+	}
+	if p := g.playerToMove(); p != nil {
+		g.emit(Event{Type: EventYourTurn, Player: p, Deadline: g.Deadline})
+	}
+}
+
+// startTimeout gives the player to move the game's timeout to move
+// before a move is made for them, replacing any running countdown
+func (g *Game) startTimeout() {
+	if g.closed {
+		return
+	}
+	g.log.Info("starting timeout")
+	deadline := time.Now().Add(g.timeout)
+	g.Deadline = &deadline
+	g.turn++
+	turn := g.turn
+	g.turnTimer().start(g.timeout, func() { g.timeoutMove(turn) })
+}
+
+// stopTimeout cancels the countdown, or the wait for the next game
+func (g *Game) stopTimeout() {
+	g.turnTimer().stop()
+	g.turn++
+	g.Deadline = nil
+}
+
+// timeoutMove places a piece in the first open spot for the player who
+// ran out of time on turn
+func (g *Game) timeoutMove(turn int) {
+	g.lock()
+	defer g.unlock()
+	// a move may have been made while the countdown fired
+	if g.closed || turn != g.turn {
+		return
+	}
+	g.log.Info("timeout received")
+	id := g.playerTurnId()
+	if id == nil {
+		g.log.Error("unable to make automatic move: could not find current player id")
+		return
+	}
+	x, y, err := g.firstOpenPositionsOnBoard()
+	if err != nil {
+		g.log.WithError(err).Error("unable to calculate random move")
+		return
+	}
+
+	g.log.WithFields(logrus.Fields{
+		"x":  x,
+		"y":  y,
+		"id": *id,
+	}).Info("placing move for user")
+	if err := g.placePiece(Move{PlayerID: *id, XAxis: x, YAxis: y}, true); err != nil {
+		g.log.WithError(err).Error("unable to place random move")
+	}
+}
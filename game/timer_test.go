@@ -0,0 +1,88 @@
+package game
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTurnTimer(t *testing.T) {
+	tCases := []struct {
+		name     string
+		run      func(t *turnTimer, fn func())
+		expected int32
+	}{
+		{
+			name:     "fires once",
+			run:      func(t *turnTimer, fn func()) { t.start(time.Millisecond, fn) },
+			expected: 1,
+		},
+		{
+			name: "starting again replaces the countdown",
+			run: func(t *turnTimer, fn func()) {
+				for i := 0; i < 10; i++ {
+					t.start(10*time.Millisecond, fn)
+				}
+			},
+			expected: 1,
+		},
+		{
+			name: "stop cancels the countdown",
+			run: func(t *turnTimer, fn func()) {
+				t.start(10*time.Millisecond, fn)
+				t.stop()
+			},
+			expected: 0,
+		},
+		{
+			name:     "stop without a countdown does nothing",
+			run:      func(t *turnTimer, fn func()) { t.stop() },
+			expected: 0,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var timer turnTimer
+			var fired int32
+			tc.run(&timer, func() { atomic.AddInt32(&fired, 1) })
+
+			<-time.After(50 * time.Millisecond)
+			timer.wait()
+			assert.Equal(t, tc.expected, atomic.LoadInt32(&fired))
+			assert.False(t, timer.running())
+		})
+	}
+}
+
+func TestTimerGoroutinesDoNotLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	g := New(logrus.WithField("test", true), time.Millisecond, nil)
+	g.nextGameDelay = time.Millisecond
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDO"}))
+	assert.NoError(t, g.AddPlayer(Player{ID: "testIDQ"}))
+
+	// every move times out, so games play themselves
+	deadline := time.Now().Add(5 * time.Second)
+	for len(g.Records()) < 50 && time.Now().Before(deadline) {
+		<-time.After(5 * time.Millisecond)
+	}
+	assert.True(t, len(g.Records()) >= 50, "expected 50 games to be played")
+
+	g.Reset()
+	g.timer.wait()
+	assert.False(t, g.timer.running())
+	assert.Nil(t, g.Deadline)
+
+	// give the runtime a moment to reap finished goroutines
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		<-time.After(time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= before, "expected no goroutines left running, had %d, now %d", before, runtime.NumGoroutine())
+}
@@ -0,0 +1,252 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Variant errors
+var (
+	ErrInvalidVariant = errors.New("invalid variant")
+	ErrInvalidBoard   = errors.New("board does not fit the variant")
+)
+
+// MaxBoardSize is the most squares a side of a Variant board can have
+const MaxBoardSize = 19
+
+// Variant is an m,n,k-game: players take turns placing pieces on a Width
+// by Height board, and the first to get K in a row across, down or
+// diagonally wins. The game served is the standard 3,3,3 variant; others
+// are for engines and analysis.
+type Variant struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	K      int `json:"k"`
+}
+
+// StandardVariant is tic tac toe
+var StandardVariant = Variant{Width: 3, Height: 3, K: 3}
+
+// Validate returns ErrInvalidVariant unless the board is 1 to
+// MaxBoardSize squares a side and K fits on it
+func (v Variant) Validate() error {
+	switch {
+	case v.Width < 1 || v.Width > MaxBoardSize || v.Height < 1 || v.Height > MaxBoardSize:
+		return fmt.Errorf("%v: sides must be 1 to %d squares", ErrInvalidVariant, MaxBoardSize)
+	case v.K < 1 || (v.K > v.Width && v.K > v.Height):
+		return fmt.Errorf("%v: k must be 1 to the longest side", ErrInvalidVariant)
+	}
+	return nil
+}
+
+func (v Variant) String() string {
+	return fmt.Sprintf("%d,%d,%d", v.Width, v.Height, v.K)
+}
+
+// Position is a position in any Variant: the pieces on the board and the
+// side to move
+type Position struct {
+	Variant Variant `json:"variant"`
+	// Board has Height rows of Width pieces, top first, holding the same
+	// pieces as a Board
+	Board [][]Piece `json:"board"`
+	// Side is to move, X or O
+	Side string `json:"side"`
+}
+
+// NewPosition returns the empty board of v with X to move
+func NewPosition(v Variant) Position {
+	p := Position{Variant: v, Side: "X", Board: make([][]Piece, v.Height)}
+	for y := range p.Board {
+		p.Board[y] = make([]Piece, v.Width)
+	}
+	return p
+}
+
+// PositionOf returns bb with side to move as a position of the standard
+// variant
+func PositionOf(bb Bitboard, side string) Position {
+	p := NewPosition(StandardVariant)
+	p.Side = side
+	for y := range p.Board {
+		for x := range p.Board[y] {
+			p.Board[y][x] = bb.At(x, y)
+		}
+	}
+	return p
+}
+
+// board returns p as an mnkBoard, checking it fits its variant and is
+// still being played
+func (p Position) board() (*mnkBoard, error) {
+	if err := p.Variant.Validate(); err != nil {
+		return nil, err
+	}
+	b := &mnkBoard{v: p.Variant, cells: make([]Piece, p.Variant.Width*p.Variant.Height)}
+	switch p.Side {
+	case "X":
+		b.side = xPiece
+	case "O":
+		b.side = oPiece
+	default:
+		return nil, ErrInvalidSide
+	}
+	if len(p.Board) != p.Variant.Height {
+		return nil, ErrInvalidBoard
+	}
+	for y, row := range p.Board {
+		if len(row) != p.Variant.Width {
+			return nil, ErrInvalidBoard
+		}
+		for x, piece := range row {
+			switch piece {
+			case xPiece, oPiece:
+				b.filled++
+			case blank:
+			default:
+				return nil, ErrInvalidBoard
+			}
+			b.cells[y*p.Variant.Width+x] = piece
+		}
+	}
+	b.status = InProgress
+	for sq, piece := range b.cells {
+		if piece != blank && b.lineThrough(sq) {
+			b.status = winner(piece)
+			break
+		}
+	}
+	if b.status == InProgress && b.filled == len(b.cells) {
+		b.status = Cats
+	}
+	if b.status != InProgress {
+		return nil, ErrNoMoves
+	}
+	return b, nil
+}
+
+func winner(p Piece) Status {
+	if p == xPiece {
+		return XWins
+	}
+	return OWins
+}
+
+// mnkBoard is a board of any variant as searched by engines, with squares
+// numbered y*Width + x
+type mnkBoard struct {
+	v     Variant
+	cells []Piece
+	// side is to move
+	side   Piece
+	filled int
+	status Status
+}
+
+// directions are the steps along a row, a column and both diagonals
+var directions = [...][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+// lineThrough reports whether the piece on sq is part of K in a row
+func (b *mnkBoard) lineThrough(sq int) bool {
+	w, h := b.v.Width, b.v.Height
+	x, y, piece := sq%w, sq/w, b.cells[sq]
+	for _, d := range directions {
+		n := 1
+		for _, dir := range [...]int{1, -1} {
+			for i := 1; ; i++ {
+				nx, ny := x+dir*i*d[0], y+dir*i*d[1]
+				if nx < 0 || nx >= w || ny < 0 || ny >= h || b.cells[ny*w+nx] != piece {
+					break
+				}
+				n++
+			}
+		}
+		if n >= b.v.K {
+			return true
+		}
+	}
+	return false
+}
+
+// play places the piece of the side to move on sq, which must be empty,
+// and passes the move to the other side
+func (b *mnkBoard) play(sq int) {
+	b.cells[sq] = b.side
+	b.filled++
+	switch {
+	case b.lineThrough(sq):
+		b.status = winner(b.side)
+	case b.filled == len(b.cells):
+		b.status = Cats
+	}
+	b.side = -b.side
+}
+
+// copyFrom makes b a copy of o, reusing b's squares
+func (b *mnkBoard) copyFrom(o *mnkBoard) {
+	cells := append(b.cells[:0], o.cells...)
+	*b = *o
+	b.cells = cells
+}
+
+// empty returns the empty squares
+func (b *mnkBoard) empty() []int {
+	squares := make([]int, 0, len(b.cells)-b.filled)
+	for sq, piece := range b.cells {
+		if piece == blank {
+			squares = append(squares, sq)
+		}
+	}
+	return squares
+}
+
+// nearby is how far from the pieces on the board candidates are looked
+// for
+const nearby = 2
+
+// candidates returns the empty squares worth searching: those within
+// nearby squares of a piece, as moves further away rarely matter on big
+// boards. Every square is a candidate on an empty board, and on boards
+// as small as the standard one.
+func (b *mnkBoard) candidates() []int {
+	if b.filled == 0 {
+		return b.empty()
+	}
+	w, h := b.v.Width, b.v.Height
+	squares := make([]int, 0, len(b.cells)-b.filled)
+	for sq, piece := range b.cells {
+		if piece != blank {
+			continue
+		}
+		x, y := sq%w, sq/w
+	search:
+		for ny := y - nearby; ny <= y+nearby; ny++ {
+			for nx := x - nearby; nx <= x+nearby; nx++ {
+				if nx >= 0 && nx < w && ny >= 0 && ny < h && b.cells[ny*w+nx] != blank {
+					squares = append(squares, sq)
+					break search
+				}
+			}
+		}
+	}
+	return squares
+}
+
+// winningMove returns the first of squares that wins for the side to
+// move
+func (b *mnkBoard) winningMove(squares []int) (int, bool) {
+	for _, sq := range squares {
+		b.cells[sq] = b.side
+		won := b.lineThrough(sq)
+		b.cells[sq] = blank
+		if won {
+			return sq, true
+		}
+	}
+	return 0, false
+}
+
+// move returns the move for sq
+func (b *mnkBoard) move(sq int) Move {
+	return Move{XAxis: sq % b.v.Width, YAxis: sq / b.v.Width}
+}
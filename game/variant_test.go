@@ -0,0 +1,147 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// positionOf builds a position of v from rows like bitboardOf, with every
+// row not given left empty
+func positionOf(v Variant, side string, rows ...string) Position {
+	p := NewPosition(v)
+	p.Side = side
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'x':
+				p.Board[y][x] = xPiece
+			case 'o':
+				p.Board[y][x] = oPiece
+			}
+		}
+	}
+	return p
+}
+
+func TestVariantValidate(t *testing.T) {
+	tCases := []struct {
+		variant Variant
+		valid   bool
+	}{
+		{variant: StandardVariant, valid: true},
+		{variant: Variant{Width: 15, Height: 15, K: 5}, valid: true},
+		{variant: Variant{Width: 7, Height: 6, K: 4}, valid: true},
+		{variant: Variant{Width: 1, Height: 5, K: 5}, valid: true},
+		{variant: Variant{Width: 0, Height: 3, K: 3}},
+		{variant: Variant{Width: 20, Height: 3, K: 3}},
+		{variant: Variant{Width: 3, Height: 3, K: 0}},
+		{variant: Variant{Width: 3, Height: 4, K: 5}},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.variant.String(), func(t *testing.T) {
+			if tc.valid {
+				assert.NoError(t, tc.variant.Validate())
+			} else {
+				assert.Error(t, tc.variant.Validate())
+			}
+		})
+	}
+}
+
+func TestPositionBoard(t *testing.T) {
+	gomoku := Variant{Width: 15, Height: 15, K: 5}
+	tCases := []struct {
+		name     string
+		position Position
+		err      error
+	}{
+		{name: "empty", position: NewPosition(gomoku)},
+		{name: "four in a row", position: positionOf(gomoku, "O", "", "", ".xxxx", "oooo")},
+		{
+			name:     "five in a row",
+			position: positionOf(gomoku, "O", "", "", ".xxxxx", "oooo"),
+			err:      ErrNoMoves,
+		},
+		{
+			name:     "five down a diagonal",
+			position: positionOf(gomoku, "O", "....x", "...x", "..x", ".x", "x", "oooo"),
+			err:      ErrNoMoves,
+		},
+		{name: "needs a side", position: positionOf(gomoku, ""), err: ErrInvalidSide},
+		{name: "rows must fit", position: Position{Variant: gomoku, Side: "X", Board: make([][]Piece, 3)}, err: ErrInvalidBoard},
+		{name: "full board", position: PositionOf(bitboardOf("xox", "xoo", "oxx"), "O"), err: ErrNoMoves},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.position.board()
+			assert.Equal(t, tc.err, err)
+		})
+	}
+
+	bad := NewPosition(StandardVariant)
+	bad.Board[1][1] = 2
+	_, err := bad.board()
+	assert.Equal(t, ErrInvalidBoard, err)
+	_, err = NewPosition(Variant{Width: 3, Height: 3, K: 4}).board()
+	assert.Error(t, err)
+}
+
+// TestMNKBoardMatchesBitboard plays random standard games on both boards
+func TestMNKBoardMatchesBitboard(t *testing.T) {
+	random := NewRandomEngine(1)
+	for i := 0; i < 200; i++ {
+		var bb Bitboard
+		b, err := NewPosition(StandardVariant).board()
+		if !assert.NoError(t, err) {
+			return
+		}
+		side := "X"
+		for bb.Status() == InProgress {
+			m, err := random.BestMove(context.Background(), bb, side)
+			assert.NoError(t, err)
+			bb, _ = bb.Play(side, m)
+			b.play(m.YAxis*3 + m.XAxis)
+			assert.Equal(t, bb.Status(), b.status)
+			if side == "X" {
+				side = "O"
+			} else {
+				side = "X"
+			}
+		}
+	}
+}
+
+func TestMNKBoardCandidates(t *testing.T) {
+	gomoku := Variant{Width: 15, Height: 15, K: 5}
+	b, err := NewPosition(gomoku).board()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, b.candidates(), 225)
+
+	// within 2 squares of a piece in the corner
+	b.play(0)
+	assert.Equal(t, []int{1, 2, 15, 16, 17, 30, 31, 32}, b.candidates())
+
+	// every empty square of the standard board is near any piece
+	b, _ = PositionOf(bitboardOf("x..", "...", "..."), "O").board()
+	assert.Equal(t, b.empty(), b.candidates())
+}
+
+func TestMNKBoardWinningMove(t *testing.T) {
+	gomoku := Variant{Width: 15, Height: 15, K: 5}
+	b, err := positionOf(gomoku, "X", "", "xxxx", "oooo").board()
+	if !assert.NoError(t, err) {
+		return
+	}
+	sq, ok := b.winningMove(b.candidates())
+	assert.True(t, ok)
+	assert.Equal(t, 19, sq)
+	assert.Equal(t, blank, b.cells[19], "expected the board to be left as it was")
+
+	b.side = oPiece
+	_, ok = b.winningMove([]int{0, 1, 2})
+	assert.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package game
+
+import "sync"
+
+// watchers are the channels receiving game updates from Watch
+type watchers struct {
+	sync.Mutex
+	chans map[chan Game]struct{}
+	// closed is set by close, after which new watches end straight away
+	closed bool
+}
+
+func newWatchers() *watchers {
+	return &watchers{chans: map[chan Game]struct{}{}}
+}
+
+// Watch returns a channel that receives the game state after every
+// change, and a function to stop watching. Slow watchers only ever see
+// the latest state; older states are dropped rather than blocking the game.
+func (g *Game) Watch() (<-chan Game, func()) {
+	g.lock()
+	if g.watch == nil {
+		g.watch = newWatchers()
+	}
+	w := g.watch
+	g.unlock()
+	ch := make(chan Game, 1)
+
+	w.Lock()
+	if w.closed {
+		close(ch)
+	} else {
+		w.chans[ch] = struct{}{}
+	}
+	w.Unlock()
+
+	stop := func() {
+		w.Lock()
+		defer w.Unlock()
+		if _, ok := w.chans[ch]; ok {
+			delete(w.chans, ch)
+			close(ch)
+		}
+	}
+	return ch, stop
+}
+
+// close ends every watch, closing their channels
+func (w *watchers) close() {
+	w.Lock()
+	defer w.Unlock()
+	w.closed = true
+	for ch := range w.chans {
+		delete(w.chans, ch)
+		close(ch)
+	}
+}
+
+// notify sends state to every watcher without blocking
+func (w *watchers) notify(state Game) {
+	if w == nil {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	for ch := range w.chans {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- state
+	}
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const graphqlSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+	subscription: Subscription
+}
+
+type Query {
+	game: Game!
+	# players are X, O and everyone in the queue
+	players: [Player!]!
+	queue: [Player!]!
+	# games are the recorded games, oldest first
+	games: [Record!]!
+	record(id: ID!): Record
+}
+
+type Mutation {
+	move(playerId: ID!, x: Int!, y: Int!): Game!
+	# subscribe adds a player to the game or the queue and returns their id,
+	# assigning one if none is given
+	subscribe(id: ID, name: String): ID!
+	unsubscribe(id: ID!): Boolean!
+	updatePlayer(id: ID!, name: String): Player!
+}
+
+type Subscription {
+	# gameUpdated sends the current game, then again after every change
+	gameUpdated: Game!
+}
+
+enum Status {
+	InsufficientPlayers
+	NoBoard
+	XWins
+	OWins
+	Cats
+	InProgress
+}
+
+type Game {
+	board: [[Int!]!]
+	queue: [Player!]!
+	playerX: Player
+	playerO: Player
+	move: String
+	status: Status!
+	history: [Move!]!
+	moveDeadline: String
+	paused: Boolean!
+	# announcement is the last message broadcast by an admin
+	announcement: String
+}
+
+type Player {
+	id: ID!
+	name: String
+	bot: Boolean!
+}
+
+type Move {
+	playerId: ID!
+	x: Int!
+	y: Int!
+	square: String!
+}
+
+type Record {
+	id: ID!
+	playerX: Player!
+	playerO: Player!
+	moves: [Move!]!
+	result: Status!
+	date: String!
+	notation: String!
+}
+`
+
+// NewGraphQLSchema parses the schema with resolvers backed by g
+func NewGraphQLSchema(g *game.Game) (*graphql.Schema, error) {
+	return graphql.ParseSchema(graphqlSchema, &gqlRoot{game: g})
+}
+
+type gqlRoot struct {
+	game *game.Game
+}
+
+// gqlGame resolves a copy of the game, so every field of a response
+// comes from the same state
+type gqlGame struct{ g game.Game }
+
+type gqlPlayer struct{ p game.Player }
+
+type gqlMove struct{ m game.Move }
+
+type gqlRecord struct{ r *game.Record }
+
+func gqlPlayers(ps []game.Player) []*gqlPlayer {
+	out := make([]*gqlPlayer, len(ps))
+	for i := range ps {
+		out[i] = &gqlPlayer{ps[i]}
+	}
+	return out
+}
+
+func gqlPlayerPtr(p *game.Player) *gqlPlayer {
+	if p == nil {
+		return nil
+	}
+	return &gqlPlayer{*p}
+}
+
+func gqlMoves(ms []game.Move) []*gqlMove {
+	out := make([]*gqlMove, len(ms))
+	for i := range ms {
+		out[i] = &gqlMove{ms[i]}
+	}
+	return out
+}
+
+func (r *gqlRoot) Game() *gqlGame { return &gqlGame{r.game.State()} }
+
+func (r *gqlRoot) Players() []*gqlPlayer {
+	state := r.game.State()
+	var ps []game.Player
+	if state.X != nil {
+		ps = append(ps, *state.X)
+	}
+	if state.O != nil {
+		ps = append(ps, *state.O)
+	}
+	return gqlPlayers(append(ps, state.Queue...))
+}
+
+func (r *gqlRoot) Queue() []*gqlPlayer { return gqlPlayers(r.game.State().Queue) }
+
+func (r *gqlRoot) Games() []*gqlRecord {
+	records := r.game.Records()
+	out := make([]*gqlRecord, len(records))
+	for i := range records {
+		out[i] = &gqlRecord{&records[i]}
+	}
+	return out
+}
+
+func (r *gqlRoot) Record(args struct{ ID graphql.ID }) *gqlRecord {
+	rec, err := r.game.Record(string(args.ID))
+	if err != nil {
+		return nil
+	}
+	return &gqlRecord{rec}
+}
+
+func (r *gqlRoot) Move(args struct {
+	PlayerID graphql.ID
+	X, Y     int32
+}) (*gqlGame, error) {
+	err := r.game.PlacePiece(game.Move{
+		PlayerID: string(args.PlayerID),
+		XAxis:    int(args.X),
+		YAxis:    int(args.Y),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gqlGame{r.game.State()}, nil
+}
+
+func (r *gqlRoot) Subscribe(args struct {
+	ID   *graphql.ID
+	Name *string
+}) (graphql.ID, error) {
+	player := game.Player{Name: args.Name}
+	if args.ID != nil {
+		player.ID = string(*args.ID)
+	}
+	if len(player.ID) == 0 {
+		id := uuid.NewV4()
+		player.ID = id.String()
+	}
+	if err := r.game.AddPlayer(player); err != nil {
+		return "", err
+	}
+	return graphql.ID(player.ID), nil
+}
+
+func (r *gqlRoot) Unsubscribe(args struct{ ID graphql.ID }) (bool, error) {
+	if err := r.game.RemovePlayer(string(args.ID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *gqlRoot) UpdatePlayer(args struct {
+	ID   graphql.ID
+	Name *string
+}) (*gqlPlayer, error) {
+	player := game.Player{ID: string(args.ID), Name: args.Name}
+	if err := r.game.UpdatePlayer(player); err != nil {
+		return nil, err
+	}
+	return &gqlPlayer{player}, nil
+}
+
+func (r *gqlRoot) GameUpdated(ctx context.Context) <-chan *gqlGame {
+	updates, stop := r.game.Watch()
+	out := make(chan *gqlGame, 1)
+	out <- &gqlGame{r.game.State()}
+	go func() {
+		defer close(out)
+		defer stop()
+		for {
+			select {
+			case g, ok := <-updates:
+				if !ok {
+					return
+				}
+				select {
+				case out <- &gqlGame{g}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (g *gqlGame) Board() *[][]int32 {
+	if g.g.Board == nil {
+		return nil
+	}
+	rows := make([][]int32, len(g.g.Board))
+	for y, row := range g.g.Board {
+		for _, p := range row {
+			rows[y] = append(rows[y], int32(p))
+		}
+	}
+	return &rows
+}
+
+func (g *gqlGame) Queue() []*gqlPlayer { return gqlPlayers(g.g.Queue) }
+
+func (g *gqlGame) PlayerX() *gqlPlayer { return gqlPlayerPtr(g.g.X) }
+
+func (g *gqlGame) PlayerO() *gqlPlayer { return gqlPlayerPtr(g.g.O) }
+
+func (g *gqlGame) Move() *string {
+	if g.g.Move == "" {
+		return nil
+	}
+	return &g.g.Move
+}
+
+func (g *gqlGame) Status() string { return string(g.g.Status) }
+
+func (g *gqlGame) History() []*gqlMove { return gqlMoves(g.g.History) }
+
+func (g *gqlGame) MoveDeadline() *string {
+	if g.g.Deadline == nil {
+		return nil
+	}
+	d := g.g.Deadline.Format(time.RFC3339Nano)
+	return &d
+}
+
+func (g *gqlGame) Paused() bool { return g.g.Paused }
+
+func (g *gqlGame) Announcement() *string {
+	if g.g.Announcement == nil {
+		return nil
+	}
+	return &g.g.Announcement.Message
+}
+
+func (p *gqlPlayer) ID() graphql.ID { return graphql.ID(p.p.ID) }
+
+func (p *gqlPlayer) Name() *string { return p.p.Name }
+
+func (p *gqlPlayer) Bot() bool { return p.p.Bot }
+
+func (m *gqlMove) PlayerID() graphql.ID { return graphql.ID(m.m.PlayerID) }
+
+func (m *gqlMove) X() int32 { return int32(m.m.XAxis) }
+
+func (m *gqlMove) Y() int32 { return int32(m.m.YAxis) }
+
+func (m *gqlMove) Square() string { return m.m.Square() }
+
+func (r *gqlRecord) ID() graphql.ID { return graphql.ID(r.r.ID) }
+
+func (r *gqlRecord) PlayerX() *gqlPlayer { return &gqlPlayer{r.r.X} }
+
+func (r *gqlRecord) PlayerO() *gqlPlayer { return &gqlPlayer{r.r.O} }
+
+func (r *gqlRecord) Moves() []*gqlMove { return gqlMoves(r.r.Moves) }
+
+func (r *gqlRecord) Result() string { return string(r.r.Result) }
+
+func (r *gqlRecord) Date() string { return r.r.Date.Format(time.RFC3339) }
+
+func (r *gqlRecord) Notation() (string, error) {
+	var buf bytes.Buffer
+	if err := game.WriteNotation(&buf, r.r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// operationType returns the type of the operation in query that runs for
+// name: query, mutation or subscription. It reads only enough of the
+// query to tell, so it is empty when the query is invalid and the schema
+// is left to report why.
+func operationType(query, name string) string {
+	type operation struct{ typ, name string }
+	var (
+		ops []operation
+		// pending is the definition whose keyword has been read, until
+		// its selection set starts
+		pending        *operation
+		braces, parens int
+	)
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end < 0 {
+				return ""
+			}
+			i += end + 6
+			continue
+		case c == '"':
+			for i++; i < len(query) && query[i] != '"'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		case c == '(':
+			parens++
+		case c == ')':
+			parens--
+		case c == '{':
+			if braces == 0 && parens == 0 {
+				if pending == nil {
+					// a selection set on its own is a query
+					ops = append(ops, operation{typ: "query"})
+				} else if pending.typ != "fragment" {
+					ops = append(ops, *pending)
+				}
+				pending = nil
+			}
+			braces++
+		case c == '}':
+			braces--
+		case c == '_' || c == '@' || unicode.IsLetter(rune(c)):
+			start := i
+			for i++; i < len(query) && (query[i] == '_' || unicode.IsLetter(rune(query[i])) || unicode.IsDigit(rune(query[i]))); i++ {
+			}
+			word := query[start:i]
+			if braces > 0 || parens > 0 || word[0] == '@' {
+				continue
+			}
+			switch {
+			case pending == nil:
+				pending = &operation{typ: word}
+			case pending.name == "":
+				pending.name = word
+			}
+			continue
+		}
+		i++
+	}
+
+	for _, op := range ops {
+		if op.name == name || (name == "" && len(ops) == 1) {
+			return op.typ
+		}
+	}
+	return ""
+}
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL executes queries and mutations, returning JSON. Subscriptions
+// are sent as server sent events, one next event per result followed by
+// a complete event, for clients that accept text/event-stream.
+func (h *Handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(err.Error()))
+				return
+			}
+		}
+		// GET must be safe, so the query can't change the game
+		if operationType(req.Query, req.OperationName) == "mutation" {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte("mutations must be sent with POST"))
+			return
+		}
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		res := h.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("streaming unsupported"))
+		return
+	}
+
+	results, err := h.schema.Subscribe(r.Context(), req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	for res := range results {
+		bs, err := json.Marshal(res)
+		if err != nil {
+			log.WithError(err).Error("error encoding graphql result")
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", bs); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+	fmt.Fprint(w, "event: complete\ndata:\n\n")
+	flusher.Flush()
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationType(t *testing.T) {
+	tCases := []struct {
+		name, query, operation, expected string
+	}{
+		{name: "shorthand query", query: "{ game { status } }", expected: "query"},
+		{name: "query", query: "query { game { status } }", expected: "query"},
+		{name: "mutation", query: `mutation { unsubscribe(id: "a") }`, expected: "mutation"},
+		{name: "named mutation", query: "mutation Leave($id: ID!) { unsubscribe(id: $id) }", expected: "mutation"},
+		{name: "subscription", query: "subscription { gameUpdated { status } }", expected: "subscription"},
+		{
+			name:      "picks the operation by name",
+			query:     "query Look { game { status } }\nmutation Leave { unsubscribe(id: \"a\") }",
+			operation: "Leave",
+			expected:  "mutation",
+		},
+		{
+			name:     "fragments are not operations",
+			query:    "fragment P on Player { id }\nquery Look { players { ...P } }",
+			expected: "query",
+		},
+		{
+			name:     "comments and strings are skipped",
+			query:    "# mutation {\nquery { record(id: \"mutation {\") { id } }",
+			expected: "query",
+		},
+		{name: "several operations without a name", query: "query A { game { status } } mutation B { unsubscribe(id: \"a\") }"},
+		{name: "unknown name", query: "query A { game { status } }", operation: "B"},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, operationType(tc.query, tc.operation))
+		})
+	}
+}
+
+// graphqlResult is the body of a GraphQL response
+type graphqlResult struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, r http.Handler, query string) graphqlResult {
+	body, err := json.Marshal(graphqlRequest{Query: query})
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(string(body))))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res graphqlResult
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	return res
+}
+
+func TestGraphQL(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+
+	tCases := []struct {
+		name     string
+		query    string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "subscribe with a name",
+			query:    `mutation { subscribe(id: "testIDX", name: "foo") }`,
+			expected: `{"subscribe": "testIDX"}`,
+		},
+		{
+			name:     "subscribe",
+			query:    `mutation { subscribe(id: "testIDO") }`,
+			expected: `{"subscribe": "testIDO"}`,
+		},
+		{
+			name:     "subscribe to the queue",
+			query:    `mutation { subscribe(id: "testIDQ") }`,
+			expected: `{"subscribe": "testIDQ"}`,
+		},
+		{
+			name:  "move",
+			query: `mutation { move(playerId: "testIDX", x: 1, y: 1) { board move status history { playerId x y square } } }`,
+			expected: `{"move": {
+				"board": [[0, 0, 0], [0, -1, 0], [0, 0, 0]],
+				"move": "O",
+				"status": "InProgress",
+				"history": [{"playerId": "testIDX", "x": 1, "y": 1, "square": "b2"}]
+			}}`,
+		},
+		{
+			name:  "move out of turn",
+			query: `mutation { move(playerId: "testIDX", x: 0, y: 0) { status } }`,
+			err:   true,
+		},
+		{
+			name:  "game",
+			query: `{ game { playerX { id name } playerO { id } queue { id } paused announcement } }`,
+			expected: `{"game": {
+				"playerX": {"id": "testIDX", "name": "foo"},
+				"playerO": {"id": "testIDO"},
+				"queue": [{"id": "testIDQ"}],
+				"paused": false,
+				"announcement": null
+			}}`,
+		},
+		{
+			name:     "players",
+			query:    `{ players { id } queue { id } }`,
+			expected: `{"players": [{"id": "testIDX"}, {"id": "testIDO"}, {"id": "testIDQ"}], "queue": [{"id": "testIDQ"}]}`,
+		},
+		{
+			name:     "update player",
+			query:    `mutation { updatePlayer(id: "testIDQ", name: "bar") { id name } }`,
+			expected: `{"updatePlayer": {"id": "testIDQ", "name": "bar"}}`,
+		},
+		{
+			name:     "unsubscribe",
+			query:    `mutation { unsubscribe(id: "testIDQ") }`,
+			expected: `{"unsubscribe": true}`,
+		},
+		{
+			name:  "unsubscribe someone not playing",
+			query: `mutation { unsubscribe(id: "testIDQ") }`,
+			err:   true,
+		},
+		{
+			name:     "no records yet",
+			query:    `{ games { id } record(id: "1") { id } }`,
+			expected: `{"games": [], "record": null}`,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := postGraphQL(t, r, tc.query)
+			if tc.err {
+				assert.NotEmpty(t, res.Errors)
+				return
+			}
+			assert.Empty(t, res.Errors)
+			assert.JSONEq(t, tc.expected, string(res.Data))
+		})
+	}
+
+	for _, m := range []game.Move{
+		{PlayerID: "testIDO", XAxis: 0, YAxis: 0},
+		{PlayerID: "testIDX", XAxis: 1, YAxis: 0},
+		{PlayerID: "testIDO", XAxis: 2, YAxis: 0},
+		{PlayerID: "testIDX", XAxis: 1, YAxis: 2},
+	} {
+		assert.NoError(t, g.PlacePiece(m))
+	}
+	res := postGraphQL(t, r, `{ games { id result playerX { id } playerO { id } moves { square } } record(id: "1") { notation } }`)
+	assert.Empty(t, res.Errors)
+	var data struct {
+		Games  []json.RawMessage
+		Record struct{ Notation string }
+	}
+	assert.NoError(t, json.Unmarshal(res.Data, &data))
+	assert.Len(t, data.Games, 1)
+	assert.JSONEq(t, `{
+		"id": "1",
+		"result": "XWins",
+		"playerX": {"id": "testIDX"},
+		"playerO": {"id": "testIDO"},
+		"moves": [{"square": "b2"}, {"square": "a1"}, {"square": "b1"}, {"square": "c1"}, {"square": "b3"}]
+	}`, string(data.Games[0]))
+	assert.Contains(t, data.Record.Notation, "b3")
+}
+
+func TestGraphQLGet(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+
+	tCases := []struct {
+		name      string
+		query     string
+		operation string
+		expected  int
+	}{
+		{name: "query", query: "{ game { status } }", expected: http.StatusOK},
+		{name: "mutation", query: `mutation { subscribe(id: "testIDX") }`, expected: http.StatusMethodNotAllowed},
+		{
+			name:      "query next to a mutation",
+			query:     `query Look { game { status } } mutation Join { subscribe(id: "testIDX") }`,
+			operation: "Look",
+			expected:  http.StatusOK,
+		},
+		{
+			name:      "mutation next to a query",
+			query:     `query Look { game { status } } mutation Join { subscribe(id: "testIDX") }`,
+			operation: "Join",
+			expected:  http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := url.Values{"query": {tc.query}, "operationName": {tc.operation}}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/graphql?"+q.Encode(), nil))
+			assert.Equal(t, tc.expected, rec.Code)
+			if tc.expected == http.StatusMethodNotAllowed {
+				assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+			}
+		})
+	}
+	assert.Nil(t, g.State().X, "expected mutations sent with GET not to run")
+}
+
+func TestGraphQLSubscription(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	r, err := Route(g, nil, Config{})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	q := url.Values{"query": {"subscription { gameUpdated { status playerX { id } } }"}}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/graphql?"+q.Encode(), nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	events := bufio.NewReader(res.Body)
+	// next reads an event, returning its name and data
+	next := func() (string, string) {
+		var event, data string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("stream ended early: %s", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data:"):
+				data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			}
+		}
+	}
+
+	event, data := next()
+	assert.Equal(t, "next", event)
+	assert.JSONEq(t, `{"data": {"gameUpdated": {"status": "InsufficientPlayers", "playerX": null}}}`, data, "expected the current game first")
+
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDX"}))
+	event, data = next()
+	assert.Equal(t, "next", event)
+	assert.JSONEq(t, `{"data": {"gameUpdated": {"status": "InsufficientPlayers", "playerX": {"id": "testIDX"}}}}`, data)
+
+	g.Close()
+	event, _ = next()
+	assert.Equal(t, "complete", event, "expected the subscription to end with the game")
+}
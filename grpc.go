@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/api"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/satori/go.uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer serves the gRPC API from the same game as the HTTP handlers
+type GRPCServer struct {
+	api.UnimplementedTicTacToeServer
+	game *game.Game
+}
+
+// NewGRPCServer returns a grpc server with the TicTacToe service registered
+func NewGRPCServer(g *game.Game) *grpc.Server {
+	s := grpc.NewServer()
+	api.RegisterTicTacToeServer(s, &GRPCServer{game: g})
+	return s
+}
+
+var statuses = map[game.Status]api.Status{
+	game.InsufficientPlayers: api.Status_INSUFFICIENT_PLAYERS,
+	game.NoBoard:             api.Status_NO_BOARD,
+	game.XWins:               api.Status_X_WINS,
+	game.OWins:               api.Status_O_WINS,
+	game.Cats:                api.Status_CATS,
+	game.InProgress:          api.Status_IN_PROGRESS,
+}
+
+func toAPIPlayer(p *game.Player) *api.Player {
+	if p == nil {
+		return nil
+	}
+	return &api.Player{Id: p.ID, Name: p.Name, Bot: p.Bot}
+}
+
+func fromAPIPlayer(p *api.Player) game.Player {
+	if p == nil {
+		return game.Player{}
+	}
+	return game.Player{ID: p.Id, Name: p.Name}
+}
+
+func toAPIGame(g game.Game) *api.Game {
+	out := &api.Game{
+		PlayerX: toAPIPlayer(g.X),
+		PlayerO: toAPIPlayer(g.O),
+		Move:    g.Move,
+		Status:  statuses[g.Status],
+	}
+	if g.Board != nil {
+		for _, row := range g.Board {
+			for _, p := range row {
+				out.Board = append(out.Board, int32(p))
+			}
+		}
+	}
+	for i := range g.Queue {
+		out.Queue = append(out.Queue, toAPIPlayer(&g.Queue[i]))
+	}
+	for _, m := range g.History {
+		out.History = append(out.History, &api.Move{
+			PlayerId: m.PlayerID,
+			XAxis:    int32(m.XAxis),
+			YAxis:    int32(m.YAxis),
+		})
+	}
+	if g.Deadline != nil {
+		out.MoveDeadline = timestamppb.New(*g.Deadline)
+	}
+	out.Paused = g.Paused
+	if g.Announcement != nil {
+		out.Announcement = g.Announcement.Message
+	}
+	return out
+}
+
+// grpcError maps game errors to status codes
+func grpcError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case game.ErrPlayerNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case game.ErrGameInProgress:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case game.ErrPlayerBanned:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func (s *GRPCServer) GetGame(ctx context.Context, _ *api.GetGameRequest) (*api.Game, error) {
+	return toAPIGame(s.game.State()), nil
+}
+
+func (s *GRPCServer) Move(ctx context.Context, req *api.MoveRequest) (*api.MoveResponse, error) {
+	m := req.GetMove()
+	if m == nil {
+		return nil, status.Error(codes.InvalidArgument, "need move")
+	}
+	err := s.game.PlacePiece(game.Move{
+		PlayerID: m.PlayerId,
+		XAxis:    int(m.XAxis),
+		YAxis:    int(m.YAxis),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &api.MoveResponse{}, nil
+}
+
+func (s *GRPCServer) Subscribe(ctx context.Context, req *api.SubscribeRequest) (*api.SubscribeResponse, error) {
+	player := fromAPIPlayer(req.GetPlayer())
+	if len(player.ID) == 0 {
+		id := uuid.NewV4()
+		player.ID = id.String()
+	}
+	if err := s.game.AddPlayer(player); err != nil {
+		return nil, grpcError(err)
+	}
+	return &api.SubscribeResponse{Id: player.ID}, nil
+}
+
+func (s *GRPCServer) Unsubscribe(ctx context.Context, req *api.UnsubscribeRequest) (*api.UnsubscribeResponse, error) {
+	if err := s.game.RemovePlayer(req.GetId()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &api.UnsubscribeResponse{}, nil
+}
+
+func (s *GRPCServer) UpdatePlayer(ctx context.Context, req *api.UpdatePlayerRequest) (*api.UpdatePlayerResponse, error) {
+	if err := s.game.UpdatePlayer(fromAPIPlayer(req.GetPlayer())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &api.UpdatePlayerResponse{}, nil
+}
+
+func (s *GRPCServer) Restart(ctx context.Context, _ *api.RestartRequest) (*api.RestartResponse, error) {
+	s.game.Restart()
+	return &api.RestartResponse{}, nil
+}
+
+func (s *GRPCServer) Clear(ctx context.Context, _ *api.ClearRequest) (*api.ClearResponse, error) {
+	s.game.Reset()
+	return &api.ClearResponse{}, nil
+}
+
+func (s *GRPCServer) WatchGame(_ *api.WatchGameRequest, stream api.TicTacToe_WatchGameServer) error {
+	updates, stop := s.game.Watch()
+	defer stop()
+
+	if err := stream.Send(toAPIGame(s.game.State())); err != nil {
+		return err
+	}
+	for {
+		select {
+		case g, ok := <-updates:
+			if !ok {
+				// the game is closed for shutdown, clients should reconnect
+				return status.Error(codes.Unavailable, "server shutting down")
+			}
+			if err := stream.Send(toAPIGame(g)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/api"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcClient serves g over an in-memory connection and returns a client
+// for it
+func grpcClient(t *testing.T, g *game.Game) api.TicTacToeClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(g)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return api.NewTicTacToeClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	c := grpcClient(t, g)
+	ctx := context.Background()
+
+	name := "foo"
+	x, err := c.Subscribe(ctx, &api.SubscribeRequest{Player: &api.Player{Name: &name}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, x.GetId())
+	o, err := c.Subscribe(ctx, &api.SubscribeRequest{Player: &api.Player{Id: "testIDO"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "testIDO", o.GetId())
+
+	_, err = c.Move(ctx, &api.MoveRequest{Move: &api.Move{PlayerId: x.GetId(), XAxis: 1, YAxis: 1}})
+	assert.NoError(t, err)
+
+	state, err := c.GetGame(ctx, &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Status_IN_PROGRESS, state.GetStatus())
+	assert.Equal(t, x.GetId(), state.GetPlayerX().GetId())
+	assert.Equal(t, "foo", state.GetPlayerX().GetName())
+	assert.Equal(t, "testIDO", state.GetPlayerO().GetId())
+	assert.Equal(t, "O", state.GetMove())
+	assert.Equal(t, []int32{0, 0, 0, 0, -1, 0, 0, 0, 0}, state.GetBoard())
+	assert.Len(t, state.GetHistory(), 1)
+	assert.NotNil(t, state.GetMoveDeadline())
+
+	newName := "bar"
+	_, err = c.UpdatePlayer(ctx, &api.UpdatePlayerRequest{Player: &api.Player{Id: "testIDO", Name: &newName}})
+	assert.NoError(t, err)
+	state, err = c.GetGame(ctx, &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", state.GetPlayerO().GetName())
+
+	_, err = c.Unsubscribe(ctx, &api.UnsubscribeRequest{Id: "testIDQ"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = c.Unsubscribe(ctx, &api.UnsubscribeRequest{Id: "testIDO"})
+	assert.NoError(t, err)
+	state, err = c.GetGame(ctx, &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Nil(t, state.GetPlayerO())
+
+	_, err = c.Restart(ctx, &api.RestartRequest{})
+	assert.NoError(t, err)
+	state, err = c.GetGame(ctx, &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, state.GetHistory())
+	assert.Equal(t, x.GetId(), state.GetPlayerX().GetId())
+
+	_, err = c.Clear(ctx, &api.ClearRequest{})
+	assert.NoError(t, err)
+	state, err = c.GetGame(ctx, &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Status_INSUFFICIENT_PLAYERS, state.GetStatus())
+	assert.Nil(t, state.GetPlayerX())
+}
+
+func TestGRPCInvalidMove(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	defer g.Close()
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDX"}))
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDO"}))
+	assert.NoError(t, g.PlacePiece(game.Move{PlayerID: "testIDX", XAxis: 1, YAxis: 1}))
+	c := grpcClient(t, g)
+
+	tCases := []struct {
+		name string
+		move *api.Move
+	}{
+		{name: "no move"},
+		{name: "out of turn", move: &api.Move{PlayerId: "testIDX", XAxis: 0, YAxis: 0}},
+		{name: "spot already used", move: &api.Move{PlayerId: "testIDO", XAxis: 1, YAxis: 1}},
+		{name: "off the board", move: &api.Move{PlayerId: "testIDO", XAxis: 3, YAxis: 0}},
+		{name: "negative", move: &api.Move{PlayerId: "testIDO", XAxis: 0, YAxis: -1}},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.Move(context.Background(), &api.MoveRequest{Move: tc.move})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+
+	state, err := c.GetGame(context.Background(), &api.GetGameRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, state.GetHistory(), 1, "expected invalid moves to leave the game alone")
+}
+
+func TestGRPCWatchGame(t *testing.T) {
+	g := game.New(logrus.WithField("test", true), time.Hour, nil)
+	c := grpcClient(t, g)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := c.WatchGame(ctx, &api.WatchGameRequest{})
+	assert.NoError(t, err)
+	state, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, api.Status_INSUFFICIENT_PLAYERS, state.GetStatus(), "expected the current game first")
+
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDX"}))
+	state, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "testIDX", state.GetPlayerX().GetId())
+
+	assert.NoError(t, g.AddPlayer(game.Player{ID: "testIDO"}))
+	state, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, api.Status_IN_PROGRESS, state.GetStatus())
+
+	g.Close()
+	for err == nil {
+		// drain states sent before the close
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.Unavailable, status.Code(err), "expected the stream to end on shutdown")
+}
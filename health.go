@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// storePingTimeout is how long readiness waits for the store to answer
+const storePingTimeout = 2 * time.Second
+
+var errStoreNotInitialized = errors.New("store not initialized")
+
+// Readiness is the result of the readiness checks. Checks maps each check
+// to "ok" or the reason it failed.
+type Readiness struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Healthz reports that the process is up and serving requests
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// Readyz reports whether the server can take traffic: the store answers,
+// or Init has set one up when a store is required, and the game's turn
+// timer is counting down as it should
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), storePingTimeout)
+	defer cancel()
+
+	rd := h.readiness(ctx)
+	w.Header().Set("Content-Type", "application/json")
+	if !rd.Ready {
+		log.WithField("checks", rd.Checks).Warn("not ready")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(rd)
+}
+
+func (h *Handler) readiness(ctx context.Context) Readiness {
+	rd := Readiness{Ready: true, Checks: map[string]string{}}
+	check := func(name string, err error) {
+		if err != nil {
+			rd.Ready = false
+			rd.Checks[name] = err.Error()
+			return
+		}
+		rd.Checks[name] = "ok"
+	}
+	check("game", h.game.Check())
+	check("store", h.pingStore(ctx))
+	return rd
+}
+
+// pingStore reads the top level keys of the store. Without a store it
+// only fails when one is required.
+func (h *Handler) pingStore(ctx context.Context) error {
+	h.storeMu.Lock()
+	store := h.store
+	h.storeMu.Unlock()
+	if store == nil {
+		if h.cfg.RequireStore {
+			return errStoreNotInitialized
+		}
+		return nil
+	}
+	var keys map[string]interface{}
+	return store.GetShallow(ctx, &keys)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are the Prometheus metrics of a server. Each server has its own
+// registry so tests can make as many as they like.
+type metrics struct {
+	registry *prometheus.Registry
+
+	gamesStarted  prometheus.Counter
+	gamesFinished *prometheus.CounterVec
+	moves         *prometheus.CounterVec
+	moveDuration  *prometheus.HistogramVec
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	storeWrites        prometheus.Counter
+	storeWriteErrors   prometheus.Counter
+	storeWriteDuration prometheus.Histogram
+}
+
+func newMetrics(g *game.Game) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		gamesStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tictactoe_games_started_total",
+			Help: "Games started.",
+		}),
+		gamesFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tictactoe_games_finished_total",
+			Help: "Games finished, by result.",
+		}, []string{"result"}),
+		moves: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tictactoe_moves_total",
+			Help: "Moves placed. auto is true for moves made when a player timed out.",
+		}, []string{"auto"}),
+		moveDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tictactoe_move_duration_seconds",
+			Help:    "How long players took to move, from the start of their turn.",
+			Buckets: []float64{.25, .5, 1, 2, 3, 4, 5, 7.5, 10, 30},
+		}, []string{"auto"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tictactoe_http_requests_total",
+			Help: "HTTP requests, by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tictactoe_http_request_duration_seconds",
+			Help:    "How long HTTP requests took, by route and method. Streams last as long as the client follows them.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		storeWrites: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tictactoe_store_writes_total",
+			Help: "Writes of the game state to the store.",
+		}),
+		storeWriteErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tictactoe_store_write_errors_total",
+			Help: "Writes of the game state to the store that failed.",
+		}),
+		storeWriteDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tictactoe_store_write_duration_seconds",
+			Help:    "How long writes of the game state to the store took.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.gamesStarted, m.gamesFinished, m.moves, m.moveDuration,
+		m.requests, m.requestDuration,
+		m.storeWrites, m.storeWriteErrors, m.storeWriteDuration,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "tictactoe_queue_length",
+			Help: "Players waiting in the queue.",
+		}, func() float64 {
+			_, queued := g.Players()
+			return float64(queued)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "tictactoe_active_players",
+			Help: "Players playing or waiting in the queue.",
+		}, func() float64 {
+			playing, queued := g.Players()
+			return float64(playing + queued)
+		}),
+	)
+	return m
+}
+
+func (m *metrics) GameStarted() { m.gamesStarted.Inc() }
+
+func (m *metrics) GameFinished(result game.Status) {
+	m.gamesFinished.WithLabelValues(string(result)).Inc()
+}
+
+func (m *metrics) MovePlaced(took time.Duration, auto bool) {
+	label := strconv.FormatBool(auto)
+	m.moves.WithLabelValues(label).Inc()
+	m.moveDuration.WithLabelValues(label).Observe(took.Seconds())
+}
+
+// storeWrite records a write to the store that started at start
+func (m *metrics) storeWrite(start time.Time, err error) {
+	m.storeWrites.Inc()
+	m.storeWriteDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.storeWriteErrors.Inc()
+	}
+}
+
+// handler serves the metrics in the Prometheus text format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// statusRecorder remembers the status code written to a response. It
+// keeps Flush so streams still work.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// instrument counts and times requests to the route with the template
+// route, so /v1/games/{id} is one series however many games there are
+func (m *metrics) instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.code)).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,252 @@
+// Package render draws game boards as SVG and raster images.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// ErrNoPositions is returned when there is nothing to animate
+var ErrNoPositions = errors.New("no positions to render")
+
+// Options are what is drawn around the board
+type Options struct {
+	XName string
+	OName string
+	// Cell is the width of a square in pixels, DefaultCell if zero
+	Cell int
+}
+
+// DefaultCell is the width of a square when Options.Cell is not set
+const DefaultCell = 100
+
+const footer = 30
+
+// Palette is the palette of images returned by Image, so frames can be
+// combined into a GIF without quantizing
+var Palette = color.Palette{
+	color.RGBA{0xff, 0xff, 0xff, 0xff}, // background
+	color.RGBA{0x33, 0x33, 0x33, 0xff}, // grid and text
+	color.RGBA{0xd3, 0x2f, 0x2f, 0xff}, // X
+	color.RGBA{0x19, 0x76, 0xd2, 0xff}, // O
+	color.RGBA{0xff, 0xeb, 0x3b, 0xff}, // winning line
+}
+
+const (
+	bgIndex uint8 = iota
+	fgIndex
+	xIndex
+	oIndex
+	winIndex
+)
+
+func hex(i uint8) string {
+	r, g, b, _ := Palette[i].RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func (o Options) cell() int {
+	if o.Cell <= 0 {
+		return DefaultCell
+	}
+	return o.Cell
+}
+
+func (o Options) footerText() string {
+	x, oName := o.XName, o.OName
+	if x == "" {
+		x = "-"
+	}
+	if oName == "" {
+		oName = "-"
+	}
+	return fmt.Sprintf("X: %s   O: %s", x, oName)
+}
+
+// winning returns the set of squares on the winning line of b
+func winning(b *game.Board) map[[2]int]bool {
+	squares := map[[2]int]bool{}
+	if line, ok := game.NewBitboard(b).WinningLine(); ok {
+		for _, sq := range line {
+			squares[sq] = true
+		}
+	}
+	return squares
+}
+
+// SVG writes b as an SVG image to w
+func SVG(w io.Writer, b *game.Board, o Options) error {
+	c := o.cell()
+	size := 3 * c
+	stroke := c / 12
+	win := winning(b)
+	bb := game.NewBitboard(b)
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		size, size+footer, size, size+footer)
+	svg += fmt.Sprintf(`<rect width="%d" height="%d" fill="%s"/>`+"\n", size, size+footer, hex(bgIndex))
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			left, top := x*c, y*c
+			if win[[2]int{x, y}] {
+				svg += fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+					left, top, c, c, hex(winIndex))
+			}
+			pad := c / 5
+			switch bb.At(x, y) {
+			case -1:
+				svg += fmt.Sprintf(`<path d="M%d %dL%d %dM%d %dL%d %d" stroke="%s" stroke-width="%d" stroke-linecap="round"/>`+"\n",
+					left+pad, top+pad, left+c-pad, top+c-pad,
+					left+c-pad, top+pad, left+pad, top+c-pad,
+					hex(xIndex), stroke)
+			case 1:
+				svg += fmt.Sprintf(`<circle cx="%d" cy="%d" r="%d" fill="none" stroke="%s" stroke-width="%d"/>`+"\n",
+					left+c/2, top+c/2, c/2-pad, hex(oIndex), stroke)
+			}
+		}
+	}
+
+	for i := 1; i < 3; i++ {
+		svg += fmt.Sprintf(`<line x1="%d" y1="0" x2="%d" y2="%d" stroke="%s" stroke-width="%d"/>`+"\n",
+			i*c, i*c, size, hex(fgIndex), stroke/2)
+		svg += fmt.Sprintf(`<line x1="0" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="%d"/>`+"\n",
+			i*c, size, i*c, hex(fgIndex), stroke/2)
+	}
+
+	svg += fmt.Sprintf(`<text x="%d" y="%d" font-family="sans-serif" font-size="14" fill="%s">%s</text>`+"\n",
+		8, size+footer-10, hex(fgIndex), html.EscapeString(o.footerText()))
+	svg += "</svg>\n"
+
+	_, err := io.WriteString(w, svg)
+	return err
+}
+
+// Image draws b onto a paletted image using Palette
+func Image(b *game.Board, o Options) *image.Paletted {
+	c := o.cell()
+	size := 3 * c
+	stroke := float64(c) / 12
+	win := winning(b)
+	bb := game.NewBitboard(b)
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size+footer), Palette)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			left, top := x*c, y*c
+			if win[[2]int{x, y}] {
+				fill(img, image.Rect(left, top, left+c, top+c), winIndex)
+			}
+
+			pad := float64(c) / 5
+			cx, cy := float64(left)+float64(c)/2, float64(top)+float64(c)/2
+			switch bb.At(x, y) {
+			case -1:
+				l, t, r, btm := float64(left)+pad, float64(top)+pad, float64(left+c)-pad, float64(top+c)-pad
+				line(img, l, t, r, btm, stroke, xIndex)
+				line(img, r, t, l, btm, stroke, xIndex)
+			case 1:
+				ring(img, cx, cy, float64(c)/2-pad, stroke, oIndex)
+			}
+		}
+	}
+
+	half := c / 48
+	if half < 1 {
+		half = 1
+	}
+	for i := 1; i < 3; i++ {
+		fill(img, image.Rect(i*c-half, 0, i*c+half, size), fgIndex)
+		fill(img, image.Rect(0, i*c-half, size, i*c+half), fgIndex)
+	}
+
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(Palette[fgIndex]),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(8, size+footer-10),
+	}
+	d.DrawString(o.footerText())
+	return img
+}
+
+// PNG writes b as a PNG image to w
+func PNG(w io.Writer, b *game.Board, o Options) error {
+	return png.Encode(w, Image(b, o))
+}
+
+// GIF writes an animation of positions to w, one frame per position with
+// delay between frames. The last frame is held for three times as long.
+func GIF(w io.Writer, positions []game.Board, o Options, delay time.Duration) error {
+	if len(positions) == 0 {
+		return ErrNoPositions
+	}
+
+	centis := int(delay / (10 * time.Millisecond))
+	anim := &gif.GIF{}
+	for i := range positions {
+		anim.Image = append(anim.Image, Image(&positions[i], o))
+		anim.Delay = append(anim.Delay, centis)
+	}
+	anim.Delay[len(anim.Delay)-1] = 3 * centis
+	return gif.EncodeAll(w, anim)
+}
+
+func fill(img *image.Paletted, r image.Rectangle, idx uint8) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetColorIndex(x, y, idx)
+		}
+	}
+}
+
+// line draws a line of width from x0, y0 to x1, y1 by coloring every
+// pixel close enough to the segment
+func line(img *image.Paletted, x0, y0, x1, y1, width float64, idx uint8) {
+	r := image.Rect(
+		int(math.Min(x0, x1)-width), int(math.Min(y0, y1)-width),
+		int(math.Max(x0, x1)+width)+1, int(math.Max(y0, y1)+width)+1,
+	).Intersect(img.Bounds())
+
+	dx, dy := x1-x0, y1-y0
+	lenSq := dx*dx + dy*dy
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			t := ((px-x0)*dx + (py-y0)*dy) / lenSq
+			t = math.Max(0, math.Min(1, t))
+			ex, ey := px-(x0+t*dx), py-(y0+t*dy)
+			if math.Sqrt(ex*ex+ey*ey) <= width/2 {
+				img.SetColorIndex(x, y, idx)
+			}
+		}
+	}
+}
+
+// ring draws a circle outline of width around cx, cy
+func ring(img *image.Paletted, cx, cy, radius, width float64, idx uint8) {
+	outer := radius + width/2
+	r := image.Rect(int(cx-outer), int(cy-outer), int(cx+outer)+1, int(cy+outer)+1).Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if math.Abs(math.Sqrt(dx*dx+dy*dy)-radius) <= width/2 {
+				img.SetColorIndex(x, y, idx)
+			}
+		}
+	}
+}
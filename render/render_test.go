@@ -0,0 +1,68 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func tstBoard() *game.Board {
+	return &game.Board{
+		{-1, -1, -1},
+		{1, 1, 0},
+		{0, 0, 0},
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, SVG(&buf, tstBoard(), Options{XName: "nat", OName: "<o>"}))
+
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, "X: nat")
+	assert.Contains(t, svg, "&lt;o&gt;", "expected player names to be escaped")
+	assert.Equal(t, 3, strings.Count(svg, hex(winIndex)), "expected the winning line to be highlighted")
+	assert.Equal(t, 3, strings.Count(svg, "<path"))
+	assert.Equal(t, 2, strings.Count(svg, "<circle"))
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, PNG(&buf, tstBoard(), Options{Cell: 50}))
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 150, img.Bounds().Dx())
+	assert.Equal(t, 150+footer, img.Bounds().Dy())
+
+	// the corner of a winning square is highlighted, a losing one is not
+	assert.Equal(t, Palette[winIndex], Palette.Convert(img.At(2, 2)))
+	assert.Equal(t, Palette[bgIndex], Palette.Convert(img.At(2, 52)))
+}
+
+func TestGIF(t *testing.T) {
+	positions := []game.Board{
+		{},
+		{{-1, 0, 0}, {0, 0, 0}, {0, 0, 0}},
+		*tstBoard(),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, GIF(&buf, positions, Options{Cell: 20}, 500*time.Millisecond))
+
+	anim, err := gif.DecodeAll(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, anim.Image, 3)
+	assert.Equal(t, []int{50, 50, 150}, anim.Delay)
+	assert.Equal(t, Palette[bgIndex], Palette.Convert(anim.Image[1].At(2, 2)))
+	assert.Equal(t, Palette[winIndex], Palette.Convert(anim.Image[2].At(2, 2)))
+
+	assert.Equal(t, ErrNoPositions, GIF(&buf, nil, Options{}, time.Second))
+}
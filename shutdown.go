@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// shutdown stops the servers and the game before ctx is done. Streams
+// are ended first so the servers can drain, then requests in flight are
+// allowed to finish, and the final state is written to the store last.
+// Background work is stopped with cancel before the final write.
+func shutdown(ctx context.Context, srv *http.Server, grpcSrv *grpc.Server, h *Handler, g *game.Game, cancel context.CancelFunc) error {
+	// no more timeouts, and every stream gets a goodbye
+	g.Close()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		log.WithError(err).Error("http requests did not finish in time")
+	}
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Error("grpc requests did not finish in time")
+		grpcSrv.Stop()
+	}
+
+	cancel()
+	if ferr := h.Flush(ctx); ferr != nil {
+		log.WithError(ferr).Error("unable to write the final state to the store")
+		if err == nil {
+			err = ferr
+		}
+	}
+	return err
+}
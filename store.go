@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	firebase "firebase.google.com/go"
+	"firebase.google.com/go/db"
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+)
+
+// maxCredentialsSize bounds the credentials sent to Init
+const maxCredentialsSize = 64 << 10
+
+var (
+	errStoreInitialized   = errors.New("store already initialized")
+	errInvalidCredentials = errors.New("credentials must be a service account key in JSON")
+	errNeedProject        = errors.New("need project id")
+)
+
+// StoreConfig says which Firebase project stores the game, and the
+// service account key used to reach it
+type StoreConfig struct {
+	ProjectID   string
+	Bucket      string
+	Credentials []byte
+}
+
+// validateCredentials checks the credentials are a service account key,
+// the only kind accepted, so other credential types can't be slipped in
+func validateCredentials(bs []byte) error {
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(bs, &key); err != nil {
+		return errInvalidCredentials
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return errInvalidCredentials
+	}
+	return nil
+}
+
+// InitStore connects to the store, writes the game to it and keeps it up
+// to date. A handler has at most one store.
+func (h *Handler) InitStore(ctx context.Context, sc StoreConfig) error {
+	_, err := h.initStore(ctx, sc)
+	return err
+}
+
+// initStore does the work of InitStore, returning the HTTP status for
+// Init along with any error
+func (h *Handler) initStore(ctx context.Context, sc StoreConfig) (int, error) {
+	if sc.ProjectID == "" {
+		return http.StatusBadRequest, errNeedProject
+	}
+	if err := validateCredentials(sc.Credentials); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if h.hasStore() {
+		return http.StatusConflict, errStoreInitialized
+	}
+
+	ref, err := database(ctx, sc)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := ref.Set(ctx, h.game); err != nil {
+		return http.StatusBadGateway, err
+	}
+
+	h.storeMu.Lock()
+	defer h.storeMu.Unlock()
+	if h.store != nil {
+		return http.StatusConflict, errStoreInitialized
+	}
+	h.store = ref
+	h.watchStore(ref)
+	return http.StatusOK, nil
+}
+
+func (h *Handler) hasStore() bool {
+	h.storeMu.Lock()
+	defer h.storeMu.Unlock()
+	return h.store != nil
+}
+
+// watchStore writes every update of the game to ref until the handler's
+// context is done
+func (h *Handler) watchStore(ref *db.Ref) {
+	updateCh := make(chan game.Game)
+	h.game.SetUpdatedCh(updateCh)
+
+	go func() {
+		ctx := h.cfg.Context
+		for {
+			select {
+			case status := <-updateCh:
+				start := time.Now()
+				err := ref.Set(ctx, status)
+				h.metrics.storeWrite(start, err)
+				if err != nil {
+					log.WithError(err).Error("error updating store")
+				}
+			case <-ctx.Done():
+				// Flush writes the final state
+				return
+			}
+		}
+	}()
+}
+
+// Flush writes the game to the store, if Init has set one up
+func (h *Handler) Flush(ctx context.Context) error {
+	h.storeMu.Lock()
+	store := h.store
+	h.storeMu.Unlock()
+	if store == nil {
+		return nil
+	}
+	start := time.Now()
+	err := store.Set(ctx, h.game)
+	h.metrics.storeWrite(start, err)
+	return err
+}
+
+// database connects to the project's realtime database. The credentials
+// are only ever held in memory.
+func database(ctx context.Context, sc StoreConfig) (*db.Ref, error) {
+	cfg := firebase.Config{
+		DatabaseURL:   fmt.Sprintf("https://%s.firebaseio.com", sc.ProjectID),
+		ProjectID:     sc.ProjectID,
+		StorageBucket: sc.Bucket,
+	}
+	opt := option.WithCredentialsJSON(sc.Credentials)
+	app, err := firebase.NewApp(ctx, &cfg, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := app.Database(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root := db.NewRef("/")
+	return root, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import "net/http"
+
+// UI serves the browser interface. It is a single page that talks to the
+// JSON API and follows /v1/game/events for updates.
+func (h *Handler) UI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(uiHTML))
+}
+
+const uiHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Tic Tac Toe</title>
+<style>
+body { font-family: sans-serif; max-width: 40em; margin: 2em auto; }
+#board { border-collapse: collapse; margin: 1em 0; }
+#board td { width: 3em; height: 3em; border: 1px solid #333; text-align: center; font-size: 2em; cursor: pointer; }
+#board td:hover { background: #eee; }
+.you { font-weight: bold; }
+#error { color: #b00; min-height: 1.2em; }
+#announcement { background: #ffd; padding: 0.5em; }
+#announcement:empty { display: none; }
+</style>
+</head>
+<body>
+<h1>Tic Tac Toe</h1>
+
+<div>
+  <input id="name" placeholder="name">
+  <button id="join">Join</button>
+  <button id="rename">Change name</button>
+  <button id="leave">Leave</button>
+</div>
+<div id="error"></div>
+<p id="announcement"></p>
+
+<p>X: <span id="player-x"></span><br>O: <span id="player-o"></span></p>
+<table id="board"></table>
+<p id="status"></p>
+
+<h2>Queue</h2>
+<ol id="queue"></ol>
+
+<script>
+(function () {
+  var me = localStorage.getItem("ttt_id") || "";
+  var state = null;
+
+  function $(id) { return document.getElementById(id); }
+
+  function showError(msg) { $("error").textContent = msg || ""; }
+
+  function request(method, path, body) {
+    return fetch(path, {
+      method: method,
+      body: body ? JSON.stringify(body) : undefined
+    }).then(function (res) {
+      return res.text().then(function (text) {
+        if (!res.ok) { throw new Error(text || res.statusText); }
+        return text;
+      });
+    });
+  }
+
+  function nameOf(p) {
+    if (!p) { return "(waiting)"; }
+    var name = p.name || p.id;
+    return p.id === me ? name + " (you)" : name;
+  }
+
+  function turnID(g) {
+    if (g.status !== "InProgress") { return ""; }
+    if (g.move === "X" && g.player_x) { return g.player_x.id; }
+    if (g.move === "O" && g.player_o) { return g.player_o.id; }
+    return "";
+  }
+
+  function render() {
+    var g = state;
+    if (!g) { return; }
+    $("announcement").textContent = g.announcement ? g.announcement.message : "";
+    $("player-x").textContent = nameOf(g.player_x);
+    $("player-o").textContent = nameOf(g.player_o);
+
+    var board = $("board");
+    board.innerHTML = "";
+    for (var y = 0; y < 3; y++) {
+      var row = board.insertRow();
+      for (var x = 0; x < 3; x++) {
+        var cell = row.insertCell();
+        var piece = g.board ? g.board[y][x] : 0;
+        cell.textContent = piece === -1 ? "X" : piece === 1 ? "O" : "";
+        cell.onclick = move.bind(null, x, y);
+      }
+    }
+
+    var status = g.status;
+    if (g.status === "InProgress") {
+      status = turnID(g) === me ? "Your move (" + g.move + ")" : g.move + " to move";
+      if (g.paused) {
+        status += ", timer paused";
+      } else if (g.move_deadline) {
+        var left = Math.max(0, (new Date(g.move_deadline) - new Date()) / 1000);
+        status += ", " + left.toFixed(0) + "s left";
+      }
+    }
+    $("status").textContent = status;
+
+    var queue = $("queue");
+    queue.innerHTML = "";
+    (g.queue || []).forEach(function (p) {
+      var li = document.createElement("li");
+      li.textContent = nameOf(p);
+      if (p.id === me) { li.className = "you"; }
+      queue.appendChild(li);
+    });
+  }
+
+  function move(x, y) {
+    if (!me) { showError("join first"); return; }
+    request("POST", "/v1/game/moves", { player_id: me, x_axis: x, y_axis: y })
+      .then(function () { showError(); }, function (err) { showError(err.message); });
+  }
+
+  $("join").onclick = function () {
+    var name = $("name").value || null;
+    request("POST", "/v1/players", { id: me, name: name }).then(function (text) {
+      me = JSON.parse(text).id;
+      localStorage.setItem("ttt_id", me);
+      showError();
+      render();
+    }, function (err) { showError(err.message); });
+  };
+
+  $("rename").onclick = function () {
+    if (!me) { showError("join first"); return; }
+    request("PUT", "/v1/players/" + encodeURIComponent(me), { id: me, name: $("name").value || null })
+      .then(function () { showError(); }, function (err) { showError(err.message); });
+  };
+
+  $("leave").onclick = function () {
+    if (!me) { return; }
+    request("DELETE", "/v1/players/" + encodeURIComponent(me)).then(function () {
+      localStorage.removeItem("ttt_id");
+      me = "";
+      showError();
+      render();
+    }, function (err) { showError(err.message); });
+  };
+
+  function poll() {
+    request("GET", "/v1/game").then(function (text) {
+      state = JSON.parse(text);
+      render();
+    }, function (err) { showError(err.message); });
+  }
+
+  if (window.EventSource) {
+    var events = new EventSource("/v1/game/events");
+    events.addEventListener("game", function (e) {
+      state = JSON.parse(e.data);
+      render();
+    });
+  } else {
+    setInterval(poll, 1000);
+  }
+  poll();
+  setInterval(render, 1000);
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.tmaws.io/nathan.hyland/tic_tac_toe/game"
+	"github.com/gorilla/mux"
+	"github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookAttempts is how many times a delivery is tried before it is
+	// dead lettered
+	webhookAttempts = 5
+	// webhookWorkers is how many deliveries are made at once
+	webhookWorkers = 4
+	// webhookQueueSize is how many deliveries can wait for a worker. Once
+	// full, new deliveries are dead lettered rather than slowing the game.
+	webhookQueueSize = 256
+	// maxDeadLetters is how many failed deliveries are kept in memory
+	maxDeadLetters = 500
+
+	// webhookPing is the event sent by the test endpoint
+	webhookPing game.EventType = "ping"
+	// defaultRoom is the room a server hosts when Config.Room is empty
+	defaultRoom = "main"
+)
+
+var (
+	errWebhookNotFound = errors.New("webhook not found")
+	errWebhookURL      = errors.New("webhook url must be an absolute http or https url")
+	errWebhookEvent    = errors.New("unknown webhook event")
+	errWebhookQueue    = errors.New("webhook queue full")
+)
+
+// webhookEvents are the events a webhook can be sent
+var webhookEvents = []game.EventType{game.EventGameOver, game.EventPlayerJoined, game.EventYourTurn}
+
+// Webhook is a URL sent a signed POST for each of its events
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Room is the room whose events are sent, or empty for every room
+	Room   string           `json:"room,omitempty"`
+	Events []game.EventType `json:"events"`
+	// Secret signs deliveries. It is only returned when the webhook is
+	// added.
+	Secret  string    `json:"secret,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// WebhookDelivery is the body POSTed to a webhook: the event, the room it
+// happened in and an ID receivers can use to ignore retries they have
+// already handled
+type WebhookDelivery struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	game.Event
+}
+
+// WebhookResult is the outcome of sending a delivery once
+type WebhookResult struct {
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DeadLetter is a delivery that failed every attempt
+type DeadLetter struct {
+	WebhookID string          `json:"webhook_id"`
+	URL       string          `json:"url"`
+	Delivery  WebhookDelivery `json:"delivery"`
+	Attempts  int             `json:"attempts"`
+	Error     string          `json:"error"`
+	Date      time.Time       `json:"date"`
+}
+
+type webhookJob struct {
+	hook     Webhook
+	delivery WebhookDelivery
+}
+
+// webhooks delivers the events of the game in room to the registered
+// webhooks
+type webhooks struct {
+	sync.Mutex
+	room        string
+	hooks       []Webhook
+	deadLetters []DeadLetter
+
+	queue  chan webhookJob
+	client *http.Client
+	// backoff is the wait before the first retry, doubled for each one
+	// after
+	backoff time.Duration
+}
+
+func newWebhooks(room string, backoff time.Duration) *webhooks {
+	if room == "" {
+		room = defaultRoom
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return &webhooks{
+		room:    room,
+		queue:   make(chan webhookJob, webhookQueueSize),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		backoff: backoff,
+	}
+}
+
+// add registers a webhook for events in room, or every event when there
+// are none and every room when room is empty, and returns it with its
+// secret
+func (wh *webhooks) add(rawURL, room string, events []game.EventType) (Webhook, error) {
+	u, ok := httpURL(rawURL)
+	if !ok {
+		return Webhook{}, errWebhookURL
+	}
+	if len(events) == 0 {
+		events = webhookEvents
+	}
+	for _, e := range events {
+		if !knownWebhookEvent(e) {
+			return Webhook{}, fmt.Errorf("%s: %s", errWebhookEvent, e)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return Webhook{}, err
+	}
+	hook := Webhook{
+		ID:      uuid.NewV4().String(),
+		URL:     u,
+		Room:    room,
+		Events:  append([]game.EventType(nil), events...),
+		Secret:  hex.EncodeToString(secret),
+		Created: time.Now().UTC(),
+	}
+
+	wh.Lock()
+	defer wh.Unlock()
+	wh.hooks = append(wh.hooks, hook)
+	return hook, nil
+}
+
+// httpURL parses an absolute http or https URL
+func httpURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func knownWebhookEvent(e game.EventType) bool {
+	for _, known := range webhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (wh *webhooks) remove(id string) error {
+	wh.Lock()
+	defer wh.Unlock()
+	for i, hook := range wh.hooks {
+		if hook.ID == id {
+			wh.hooks = append(wh.hooks[:i], wh.hooks[i+1:]...)
+			return nil
+		}
+	}
+	return errWebhookNotFound
+}
+
+func (wh *webhooks) get(id string) (Webhook, error) {
+	wh.Lock()
+	defer wh.Unlock()
+	for _, hook := range wh.hooks {
+		if hook.ID == id {
+			return hook, nil
+		}
+	}
+	return Webhook{}, errWebhookNotFound
+}
+
+// list returns the webhooks, oldest first, without their secrets
+func (wh *webhooks) list() []Webhook {
+	wh.Lock()
+	defer wh.Unlock()
+	hooks := make([]Webhook, len(wh.hooks))
+	for i, hook := range wh.hooks {
+		hook.Secret = ""
+		hooks[i] = hook
+	}
+	return hooks
+}
+
+func (wh *webhooks) deadLetterList() []DeadLetter {
+	wh.Lock()
+	defer wh.Unlock()
+	return append([]DeadLetter{}, wh.deadLetters...)
+}
+
+func (wh *webhooks) deadLetter(job webhookJob, attempts int, err error) {
+	log.WithFields(log.Fields{
+		"webhook_id":  job.hook.ID,
+		"url":         job.hook.URL,
+		"delivery_id": job.delivery.ID,
+		"event":       job.delivery.Type,
+		"attempts":    attempts,
+	}).WithError(err).Error("webhook delivery failed")
+
+	wh.Lock()
+	defer wh.Unlock()
+	wh.deadLetters = append(wh.deadLetters, DeadLetter{
+		WebhookID: job.hook.ID,
+		URL:       job.hook.URL,
+		Delivery:  job.delivery,
+		Attempts:  attempts,
+		Error:     err.Error(),
+		Date:      time.Now().UTC(),
+	})
+	if len(wh.deadLetters) > maxDeadLetters {
+		wh.deadLetters = wh.deadLetters[len(wh.deadLetters)-maxDeadLetters:]
+	}
+}
+
+// notify queues e for every webhook that wants it. It is called by the
+// game, so it never blocks.
+func (wh *webhooks) notify(e game.Event) {
+	wh.Lock()
+	var jobs []webhookJob
+	for _, hook := range wh.hooks {
+		if hook.Room != "" && hook.Room != wh.room {
+			continue
+		}
+		for _, want := range hook.Events {
+			if want == e.Type {
+				jobs = append(jobs, webhookJob{
+					hook:     hook,
+					delivery: WebhookDelivery{ID: uuid.NewV4().String(), Room: wh.room, Event: e},
+				})
+				break
+			}
+		}
+	}
+	wh.Unlock()
+
+	for _, job := range jobs {
+		select {
+		case wh.queue <- job:
+		default:
+			wh.deadLetter(job, 0, errWebhookQueue)
+		}
+	}
+}
+
+// run delivers queued events until ctx is done
+func (wh *webhooks) run(ctx context.Context) {
+	for i := 0; i < webhookWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case job := <-wh.queue:
+					wh.send(ctx, job)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// send tries a delivery until it succeeds, fails for good or runs out of
+// attempts, waiting longer between each one
+func (wh *webhooks) send(ctx context.Context, job webhookJob) {
+	wait := wh.backoff
+	var res WebhookResult
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var err error
+		res, err = wh.deliver(ctx, job)
+		if err == nil {
+			return
+		}
+		if !retryable(res.StatusCode) || attempt == webhookAttempts {
+			wh.deadLetter(job, attempt, err)
+			return
+		}
+
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-ctx.Done():
+			wh.deadLetter(job, attempt, err)
+			return
+		}
+	}
+}
+
+// retryable reports whether a delivery that got code, or no response
+// when code is 0, may succeed if tried again
+func retryable(code int) bool {
+	return code == 0 || code == http.StatusTooManyRequests || code >= 500
+}
+
+// deliver POSTs the delivery to the webhook once. Any status but 2xx is
+// an error.
+func (wh *webhooks) deliver(ctx context.Context, job webhookJob) (WebhookResult, error) {
+	body, err := json.Marshal(job.delivery)
+	if err != nil {
+		return WebhookResult{Error: err.Error()}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, job.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return WebhookResult{Error: err.Error()}, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tic_tac_toe-webhooks")
+	req.Header.Set("X-Webhook-Event", string(job.delivery.Type))
+	req.Header.Set("X-Webhook-Delivery", job.delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(job.hook.Secret, timestamp, body))
+
+	res, err := wh.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return WebhookResult{Error: err.Error()}, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 1<<20))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := fmt.Errorf("webhook returned %s", res.Status)
+		return WebhookResult{StatusCode: res.StatusCode, Error: err.Error()}, err
+	}
+	return WebhookResult{StatusCode: res.StatusCode}, nil
+}
+
+// signWebhook is the hex HMAC-SHA256 of the timestamp, a dot and the
+// body, keyed with the webhook's secret. Signing the timestamp lets
+// receivers reject old deliveries being replayed.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AdminAddWebhook registers a webhook. Takes a body of
+// {"url": string, "room": string, "events": [string]} and returns the
+// webhook with the secret its deliveries are signed with.
+func (h *Handler) AdminAddWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL    string           `json:"url"`
+		Room   string           `json:"room"`
+		Events []game.EventType `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	hook, err := h.webhooks.add(req.URL, req.Room, req.Events)
+	h.audit(r, "add_webhook", hook.ID, req.URL, err)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// AdminWebhooks lists the webhooks, without their secrets
+func (h *Handler) AdminWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.webhooks.list())
+}
+
+// AdminRemoveWebhook stops sending events to a webhook
+func (h *Handler) AdminRemoveWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.writeAdmin(w, r, "remove_webhook", id, "", h.webhooks.remove(id))
+}
+
+// AdminTestWebhook sends a ping event to a webhook once, straight away,
+// and returns what happened
+func (h *Handler) AdminTestWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	hook, err := h.webhooks.get(id)
+	if err != nil {
+		h.writeAdmin(w, r, "test_webhook", id, "", err)
+		return
+	}
+
+	job := webhookJob{hook: hook, delivery: WebhookDelivery{
+		ID:    uuid.NewV4().String(),
+		Room:  h.webhooks.room,
+		Event: game.Event{Type: webhookPing, Date: time.Now().UTC()},
+	}}
+	res, err := h.webhooks.deliver(r.Context(), job)
+	h.audit(r, "test_webhook", id, "", err)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// AdminDeadLetters lists deliveries that failed every attempt, oldest
+// first
+func (h *Handler) AdminDeadLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.webhooks.deadLetterList())
+}